@@ -0,0 +1,247 @@
+package emqx
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Authenticator produce the Authorization header of requests
+type Authenticator interface {
+	Authorization() (string, error)
+}
+
+// secretHolder is implemented by authenticators knowing the secret they authenticate with,
+// so it can be redacted from logs
+type secretHolder interface {
+	secret() string
+}
+
+// invalidator is implemented by authenticators holding a session,
+// the session is dropped when EMQX answers 401 so it can be obtained again
+type invalidator interface {
+	Invalidate()
+}
+
+// BasicAuthenticator HTTP Basic authentication with application credentials
+type BasicAuthenticator struct {
+	credentials CredentialsProvider
+
+	mu        sync.Mutex
+	appID     string
+	appSecret string
+	token     string
+}
+
+// NewBasicAuthenticator create a Basic authenticator from a credentials provider
+func NewBasicAuthenticator(p CredentialsProvider) *BasicAuthenticator {
+	return &BasicAuthenticator{credentials: p}
+}
+
+// Authorization build the Basic token from current credentials
+func (b *BasicAuthenticator) Authorization() (string, error) {
+	appID, appSecret, err := b.credentials.Credentials()
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token == "" || appID != b.appID || appSecret != b.appSecret {
+		b.updateToken(appID, appSecret)
+	}
+	return b.token, nil
+}
+
+// secret the application secret of the last request, without asking the provider again
+func (b *BasicAuthenticator) secret() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.appSecret
+}
+
+// UpdateToken update token with appID and appSecret, b.mu must be held
+func (b *BasicAuthenticator) updateToken(appID, appSecret string) {
+	b.appID = appID
+	b.appSecret = appSecret
+	b.token = basicToken(appID, appSecret)
+}
+
+// BearerToken static bearer token authentication, e.g. an EMQX v5 token
+type BearerToken string
+
+// Authorization return the bearer token
+func (t BearerToken) Authorization() (string, error) {
+	return "Bearer " + string(t), nil
+}
+
+func (t BearerToken) secret() string {
+	return string(t)
+}
+
+func basicToken(username, password string) string {
+	str := fmt.Sprintf("%s:%s", username, password)
+	return fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(str)))
+}
+
+// LoginConfig login authenticator config
+type LoginConfig struct {
+	// EMQX API base url, defaul to http://localhost:8080
+	BaseURL string
+	// Dashboard username
+	Username string
+	// Dashboard password
+	Password string
+	// LoginPath login endpoint, default to api/v3/auth, api/v5/login for EMQX v5
+	LoginPath string
+	// TokenTTL time after which the session token is refreshed, default to 30m
+	TokenTTL time.Duration
+	// Timeout bound each login, requests waiting for the session wait at most as long, default to 5s
+	Timeout time.Duration
+	// TLS options for https base urls, default to the TLS options and transport of the client using it
+	TLS *TLSConfig
+	// HTTPClient client used to login, default to a client with Timeout and TLS
+	HTTPClient *http.Client
+}
+
+// LoginAuthenticator authenticate as a dashboard user.
+// It logs in with the username and password, keeps the returned session token,
+// and logs in again once the token is older than TokenTTL or rejected by EMQX.
+// Brokers answering the login without a token (EMQX v3 dashboard) are
+// accessed with Basic authentication of the verified user.
+type LoginAuthenticator struct {
+	config LoginConfig
+	// defaultClient login with the transport of the client using it, neither HTTPClient nor TLS are set
+	defaultClient bool
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// loginResponse login result, token is under data for v3/v4 and top level for v5
+type loginResponse struct {
+	Code    int
+	Message string
+	Token   string `json:"token"`
+	Data    struct {
+		Token string `json:"token"`
+	}
+}
+
+// NewLoginAuthenticator create a dashboard user authenticator
+func NewLoginAuthenticator(c LoginConfig) *LoginAuthenticator {
+	if c.BaseURL == "" {
+		c.BaseURL = "http://localhost:8080"
+	}
+	if c.LoginPath == "" {
+		c.LoginPath = "api/v3/auth"
+	}
+	if c.TokenTTL == 0 {
+		c.TokenTTL = time.Minute * 30
+	}
+	if c.Timeout == 0 {
+		c.Timeout = time.Second * 5
+	}
+	defaultClient := c.HTTPClient == nil && c.TLS == nil
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: c.Timeout}
+		if c.TLS != nil {
+			c.HTTPClient.Transport = NewTLSTransport(*c.TLS)
+		}
+	}
+	return &LoginAuthenticator{config: c, defaultClient: defaultClient}
+}
+
+// useTransport login through rt, the transport of the client using l,
+// unless l has its own HTTPClient or TLS
+func (l *LoginAuthenticator) useTransport(rt http.RoundTripper) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.defaultClient {
+		l.config.HTTPClient = &http.Client{Timeout: l.config.Timeout, Transport: rt}
+	}
+}
+
+// Authorization return the session token, logging in if needed
+func (l *LoginAuthenticator) Authorization() (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token != "" && time.Now().Before(l.expiresAt) {
+		return l.token, nil
+	}
+
+	// the lock is held while logging in, the deadline bounds the wait of other requests
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.Timeout)
+	defer cancel()
+	token, err := l.login(ctx)
+	if err != nil {
+		return "", err
+	}
+	l.token = token
+	l.expiresAt = time.Now().Add(l.config.TokenTTL)
+	return l.token, nil
+}
+
+// secret the dashboard password
+func (l *LoginAuthenticator) secret() string {
+	return l.config.Password
+}
+
+// Invalidate drop the session token, the next request logs in again
+func (l *LoginAuthenticator) Invalidate() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.token = ""
+}
+
+func (l *LoginAuthenticator) login(ctx context.Context) (string, error) {
+	payload, err := json.Marshal(AuthUserRequestV3{
+		Username: l.config.Username,
+		Password: l.config.Password,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/%s", l.config.BaseURL, l.config.LoginPath)
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return "", err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json")
+	request.SetBasicAuth(l.config.Username, l.config.Password)
+
+	response, err := l.config.HTTPClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	content, _ := ioutil.ReadAll(response.Body)
+
+	var resp loginResponse
+	if err := json.Unmarshal(content, &resp); err != nil {
+		return "", errors.New(string(content))
+	}
+	if response.StatusCode != http.StatusOK || resp.Code != 0 {
+		return "", fmt.Errorf("login as %s failed: %s", l.config.Username, string(content))
+	}
+
+	switch {
+	case resp.Token != "":
+		return "Bearer " + resp.Token, nil
+	case resp.Data.Token != "":
+		return "Bearer " + resp.Data.Token, nil
+	default:
+		return basicToken(l.config.Username, l.config.Password), nil
+	}
+}
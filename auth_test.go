@@ -0,0 +1,73 @@
+package emqx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoginAuthenticator(t *testing.T) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/auth" {
+			logins++
+			fmt.Fprintf(w, `{"code":0,"data":{"token":"token%d"}}`, logins)
+			return
+		}
+		// the first session is expired on the broker side
+		if r.Header.Get("Authorization") != "Bearer token2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":401}`))
+			return
+		}
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer server.Close()
+
+	auth := NewLoginAuthenticator(LoginConfig{BaseURL: server.URL, Username: "admin", Password: "public"})
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL, Authenticator: auth})
+	resp, err := a.ListCluster()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != 0 || logins != 2 {
+		t.Fatal(resp.Code, logins)
+	}
+}
+
+func TestLoginAuthenticatorBasicFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer server.Close()
+
+	auth := NewLoginAuthenticator(LoginConfig{BaseURL: server.URL, Username: "admin", Password: "public"})
+	token, err := auth.Authorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "Basic YWRtaW46cHVibGlj" {
+		t.Fatal(token)
+	}
+}
+
+func TestLoginAuthenticatorTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// a client without timeout is still bounded by the login deadline
+	auth := NewLoginAuthenticator(LoginConfig{BaseURL: server.URL, Timeout: time.Millisecond * 50, HTTPClient: &http.Client{}})
+	start := time.Now()
+	if _, err := auth.Authorization(); err == nil {
+		t.Fatal("expected login to time out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("login took %s", elapsed)
+	}
+}
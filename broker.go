@@ -0,0 +1,131 @@
+package emqx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound requested resource does not exist on the broker
+var ErrNotFound = errors.New("emqx: not found")
+
+// Broker version neutral view of an EMQX cluster.
+// Use NewBroker to adapt a client of any supported API version,
+// so application code does not depend on the wire format.
+type Broker interface {
+	// APIVersion REST API version of the underlying client
+	APIVersion() APIVersion
+
+	// ListNodes list all nodes in the cluster
+	ListNodes() ([]Node, error)
+
+	// ListConnections list all connections in the cluster
+	ListConnections() ([]Connection, error)
+
+	// GetConnection retrieve a connection in the cluster, ErrNotFound if absent
+	GetConnection(clientid string) (*Connection, error)
+
+	// ListSessions list all sessions in the cluster
+	ListSessions() ([]Session, error)
+
+	// ListSubscriptions list all subscriptions in the cluster
+	ListSubscriptions() ([]Subscription, error)
+
+	// ListClientSubscriptions list subscriptions of a client in the cluster
+	ListClientSubscriptions(clientid string) ([]Subscription, error)
+
+	// GetMetrics get metrics aggregated over the cluster
+	GetMetrics() (Metrics, error)
+
+	// GetNodeMetrics get metrics of a node
+	GetNodeMetrics(node string) (Metrics, error)
+
+	// Publish publish a message
+	Publish(msg *Message) error
+}
+
+// Node cluster node
+type Node struct {
+	Name        string
+	Status      string
+	Version     string
+	Uptime      string
+	Connections int
+}
+
+// Connection client connection
+type Connection struct {
+	ClientID    string
+	Username    string
+	Node        string
+	Connected   bool
+	ConnectedAt string
+	IPAddress   string
+	Port        int
+	ProtoName   string
+	ProtoVer    int
+	KeepAlive   int
+	CleanStart  bool
+	IsBridge    bool
+}
+
+// Session client session
+type Session struct {
+	ClientID           string
+	Username           string
+	Node               string
+	CleanStart         bool
+	CreatedAt          string
+	ExpiryInterval     int
+	SubscriptionsCount int
+	InflightLen        int
+	MqueueLen          int
+	MqueueDropped      int
+}
+
+// Subscription topic subscription of a client
+type Subscription struct {
+	ClientID string
+	Node     string
+	Topic    string
+	Qos      int
+}
+
+// Metrics counters keyed by dot separated name, e.g. messages.received
+type Metrics map[string]int64
+
+// Message message to publish
+type Message struct {
+	Topic    string
+	Payload  string
+	Qos      int
+	Retain   bool
+	ClientID string
+}
+
+// NewBroker adapt a Client, ClientV4 or ClientV5, e.g. the result of Discover
+func NewBroker(c interface{}) (Broker, error) {
+	switch c := c.(type) {
+	case Client:
+		return NewBrokerV3(c), nil
+	case ClientV4:
+		return NewBrokerV4(c), nil
+	case ClientV5:
+		return NewBrokerV5(c), nil
+	default:
+		return nil, fmt.Errorf("emqx: unsupported client %T", c)
+	}
+}
+
+// metricName normalize metric names, v3 separates with slashes
+func metricName(name string) string {
+	return strings.Replace(name, "/", ".", -1)
+}
+
+// codeError turn a non zero v3/v4 response code into an error
+func codeError(code int) error {
+	if code == 0 {
+		return nil
+	}
+	return fmt.Errorf("emqx: response code %d", code)
+}
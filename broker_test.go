@@ -0,0 +1,85 @@
+package emqx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBrokerV3(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/nodes/":
+			w.Write([]byte(`{"code":0,"data":[{"connections":2,"load1":"0.10","max_fds":1048576,
+				"memory_total":"155.1M","name":"emqx","node":"emqx@node1","node_status":"Running",
+				"otp_release":"R21/10.3.2","process_available":262144,"process_used":1024,
+				"uptime":"3 hours, 1 minutes","version":"v3.2.7"}]}`))
+		case "/api/v3/metrics/":
+			w.Write([]byte(`{"code":0,"data":[
+				{"node":"emqx@node1","metrics":[{"messages/received":3}]},
+				{"node":"emqx@node2","metrics":[{"messages/received":4}]}]}`))
+		case "/api/v3/subscriptions/c1":
+			w.Write([]byte(`{"code":0,"data":[{"client_id":"c1","node":"emqx@node1","qos":"1","topic":"a/b"}]}`))
+		case "/api/v3/connections/c2":
+			w.Write([]byte(`{"code":0,"data":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	b, err := NewBroker(NewAPIClient(ClientConfig{BaseURL: server.URL}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := b.ListNodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].Name != "emqx@node1" || nodes[0].Status != "Running" ||
+		nodes[0].Version != "v3.2.7" || nodes[0].Uptime != "3 hours, 1 minutes" || nodes[0].Connections != 2 {
+		t.Fatal(nodes)
+	}
+
+	metrics, err := b.GetMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics["messages.received"] != 7 {
+		t.Fatal(metrics)
+	}
+
+	subs, err := b.ListClientSubscriptions("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Qos != 1 || subs[0].Topic != "a/b" {
+		t.Fatal(subs)
+	}
+
+	if _, err := b.GetConnection("c2"); err != ErrNotFound {
+		t.Fatal(err)
+	}
+}
+
+func TestBrokerV5ListConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.Write([]byte(`{"data":[{"clientid":"c1","connected":true}],"meta":{"page":1,"hasnext":true}}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"clientid":"c2","connected":false}],"meta":{"page":2,"hasnext":false}}`))
+	}))
+	defer server.Close()
+
+	b, err := NewBroker(NewAPIClientV5(ClientConfig{BaseURL: server.URL}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns, err := b.ListConnections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conns) != 2 || !conns[0].Connected || conns[1].ClientID != "c2" {
+		t.Fatal(conns)
+	}
+}
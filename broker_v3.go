@@ -0,0 +1,212 @@
+package emqx
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type brokerV3 struct {
+	c Client
+}
+
+// NewBrokerV3 adapt a v3 Client to Broker
+func NewBrokerV3(c Client) Broker {
+	return &brokerV3{c: c}
+}
+
+func (b *brokerV3) APIVersion() APIVersion {
+	return APIVersionV3
+}
+
+func (b *brokerV3) ListNodes() ([]Node, error) {
+	resp, err := b.c.ListNodeStats()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	nodes := make([]Node, 0, len(resp.Data))
+	for _, n := range resp.Data {
+		nodes = append(nodes, Node{
+			Name:        n.Node,
+			Status:      n.NodeStatus,
+			Version:     n.Version,
+			Uptime:      n.Uptime,
+			Connections: n.Connections,
+		})
+	}
+	return nodes, nil
+}
+
+func (b *brokerV3) ListConnections() ([]Connection, error) {
+	resp, err := b.c.ListClusterConnections()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	return connectionsFromV3(resp.Data), nil
+}
+
+func (b *brokerV3) GetConnection(clientid string) (*Connection, error) {
+	resp, err := b.c.GetClusterConnection(clientid)
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, ErrNotFound
+	}
+	return &connectionsFromV3(resp.Data)[0], nil
+}
+
+func (b *brokerV3) ListSessions() ([]Session, error) {
+	resp, err := b.c.ListClusterSessions()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, 0, len(resp.Data))
+	for _, s := range resp.Data {
+		sessions = append(sessions, Session{
+			ClientID:           s.ClientID,
+			Username:           s.Username,
+			Node:               s.Node,
+			CleanStart:         s.CleanStart,
+			CreatedAt:          s.CreatedAt,
+			ExpiryInterval:     s.ExpiryInterval,
+			SubscriptionsCount: s.SubscriptionsCount,
+			InflightLen:        s.InflightLen,
+			MqueueLen:          s.MqueueLen,
+			MqueueDropped:      s.MqueueDropped,
+		})
+	}
+	return sessions, nil
+}
+
+func (b *brokerV3) ListSubscriptions() ([]Subscription, error) {
+	resp, err := b.c.ListClusterSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	return subscriptionsFromV3(resp.Data), nil
+}
+
+func (b *brokerV3) ListClientSubscriptions(clientid string) ([]Subscription, error) {
+	resp, err := b.c.ListClusterConnSubscriptions(clientid)
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	return subscriptionsFromV3(resp.Data), nil
+}
+
+func (b *brokerV3) GetMetrics() (Metrics, error) {
+	resp, err := b.c.ListClusterMetrics()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	metrics := Metrics{}
+	for _, n := range resp.Data {
+		for _, m := range n.Metrics {
+			if err := addMetricsV3(metrics, m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return metrics, nil
+}
+
+func (b *brokerV3) GetNodeMetrics(node string) (Metrics, error) {
+	resp, err := b.c.GetNodeMetrics(node)
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	metrics := Metrics{}
+	if err := addMetricsV3(metrics, resp.Data); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+func (b *brokerV3) Publish(msg *Message) error {
+	resp, err := b.c.PublishMessage(&PublishMessageRequestV3{
+		Topic:    msg.Topic,
+		Payload:  msg.Payload,
+		Qos:      msg.Qos,
+		Retain:   msg.Retain,
+		ClientID: msg.ClientID,
+	})
+	if err != nil {
+		return err
+	}
+	return codeError(resp.Code)
+}
+
+func connectionsFromV3(data []ConnectionV3) []Connection {
+	conns := make([]Connection, 0, len(data))
+	for _, c := range data {
+		conns = append(conns, Connection{
+			ClientID:    c.ClientID,
+			Username:    c.Username,
+			Node:        c.Node,
+			Connected:   true,
+			ConnectedAt: c.ConnectedAT,
+			IPAddress:   c.IPAddress,
+			Port:        c.Port,
+			ProtoName:   c.ProtoName,
+			ProtoVer:    c.ProtoVer,
+			KeepAlive:   c.KeepAlive,
+			CleanStart:  c.CleanStart,
+			IsBridge:    c.IsBridge,
+		})
+	}
+	return conns
+}
+
+func subscriptionsFromV3(data []SubscriptionV3) []Subscription {
+	subs := make([]Subscription, 0, len(data))
+	for _, s := range data {
+		qos, _ := strconv.Atoi(s.Qos)
+		subs = append(subs, Subscription{
+			ClientID: s.ClientID,
+			Node:     s.Node,
+			Topic:    s.Topic,
+			Qos:      qos,
+		})
+	}
+	return subs
+}
+
+// addMetricsV3 add the counters of a MetricsV3 to metrics, using its json names
+func addMetricsV3(metrics Metrics, m MetricsV3) error {
+	content, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	var counters map[string]int64
+	if err := json.Unmarshal(content, &counters); err != nil {
+		return err
+	}
+	for k, v := range counters {
+		metrics[metricName(k)] += v
+	}
+	return nil
+}
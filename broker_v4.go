@@ -0,0 +1,210 @@
+package emqx
+
+// brokerPageLimit page size used to walk paginated lists
+const brokerPageLimit = 1000
+
+type brokerV4 struct {
+	c ClientV4
+}
+
+// NewBrokerV4 adapt a ClientV4 to Broker
+func NewBrokerV4(c ClientV4) Broker {
+	return &brokerV4{c: c}
+}
+
+func (b *brokerV4) APIVersion() APIVersion {
+	return APIVersionV4
+}
+
+func (b *brokerV4) ListNodes() ([]Node, error) {
+	resp, err := b.c.ListNodeStats()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	nodes := make([]Node, 0, len(resp.Data))
+	for _, n := range resp.Data {
+		nodes = append(nodes, Node{
+			Name:        n.Node,
+			Status:      n.NodeStatus,
+			Version:     n.Version,
+			Uptime:      n.Uptime,
+			Connections: n.Connections,
+		})
+	}
+	return nodes, nil
+}
+
+// listClients walk all pages of clients
+func (b *brokerV4) listClients() ([]ConnectionV4, error) {
+	clients := []ConnectionV4{}
+	for page := 1; ; page++ {
+		resp, err := b.c.ListClients(&PageV4{Page: page, Limit: brokerPageLimit})
+		if err != nil {
+			return nil, err
+		}
+		if err := codeError(resp.Code); err != nil {
+			return nil, err
+		}
+		clients = append(clients, resp.Data...)
+		if len(resp.Data) < brokerPageLimit || (len(clients) >= resp.Meta.Count && !resp.Meta.HasNext) {
+			return clients, nil
+		}
+	}
+}
+
+func (b *brokerV4) ListConnections() ([]Connection, error) {
+	clients, err := b.listClients()
+	if err != nil {
+		return nil, err
+	}
+	conns := make([]Connection, 0, len(clients))
+	for _, c := range clients {
+		conns = append(conns, connectionFromV4(c))
+	}
+	return conns, nil
+}
+
+func (b *brokerV4) GetConnection(clientid string) (*Connection, error) {
+	resp, err := b.c.GetClient(clientid)
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, ErrNotFound
+	}
+	conn := connectionFromV4(resp.Data[0])
+	return &conn, nil
+}
+
+func (b *brokerV4) ListSessions() ([]Session, error) {
+	clients, err := b.listClients()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, 0, len(clients))
+	for _, c := range clients {
+		sessions = append(sessions, Session{
+			ClientID:           c.ClientID,
+			Username:           c.Username,
+			Node:               c.Node,
+			CleanStart:         c.CleanStart,
+			CreatedAt:          c.CreatedAt,
+			ExpiryInterval:     c.ExpiryInterval,
+			SubscriptionsCount: c.SubscriptionsCnt,
+			InflightLen:        c.Inflight,
+			MqueueLen:          c.MqueueLen,
+			MqueueDropped:      c.MqueueDropped,
+		})
+	}
+	return sessions, nil
+}
+
+func (b *brokerV4) ListSubscriptions() ([]Subscription, error) {
+	subs := []Subscription{}
+	for page := 1; ; page++ {
+		resp, err := b.c.ListSubscriptions(&PageV4{Page: page, Limit: brokerPageLimit})
+		if err != nil {
+			return nil, err
+		}
+		if err := codeError(resp.Code); err != nil {
+			return nil, err
+		}
+		subs = append(subs, subscriptionsFromV4(resp.Data)...)
+		if len(resp.Data) < brokerPageLimit || (len(subs) >= resp.Meta.Count && !resp.Meta.HasNext) {
+			return subs, nil
+		}
+	}
+}
+
+func (b *brokerV4) ListClientSubscriptions(clientid string) ([]Subscription, error) {
+	resp, err := b.c.ListClientSubscriptions(clientid)
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	return subscriptionsFromV4(resp.Data), nil
+}
+
+func (b *brokerV4) GetMetrics() (Metrics, error) {
+	resp, err := b.c.ListClusterMetrics()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	metrics := Metrics{}
+	for _, n := range resp.Data {
+		for k, v := range n.Metrics {
+			metrics[metricName(k)] += v
+		}
+	}
+	return metrics, nil
+}
+
+func (b *brokerV4) GetNodeMetrics(node string) (Metrics, error) {
+	resp, err := b.c.GetNodeMetrics(node)
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	metrics := Metrics{}
+	for k, v := range resp.Data {
+		metrics[metricName(k)] = v
+	}
+	return metrics, nil
+}
+
+func (b *brokerV4) Publish(msg *Message) error {
+	resp, err := b.c.PublishMessage(&PublishMessageRequestV4{
+		Topic:    msg.Topic,
+		Payload:  msg.Payload,
+		Qos:      msg.Qos,
+		Retain:   msg.Retain,
+		ClientID: msg.ClientID,
+	})
+	if err != nil {
+		return err
+	}
+	return codeError(resp.Code)
+}
+
+func connectionFromV4(c ConnectionV4) Connection {
+	return Connection{
+		ClientID:    c.ClientID,
+		Username:    c.Username,
+		Node:        c.Node,
+		Connected:   c.Connected,
+		ConnectedAt: c.ConnectedAt,
+		IPAddress:   c.IPAddress,
+		Port:        c.Port,
+		ProtoName:   c.ProtoName,
+		ProtoVer:    c.ProtoVer,
+		KeepAlive:   c.KeepAlive,
+		CleanStart:  c.CleanStart,
+		IsBridge:    c.IsBridge,
+	}
+}
+
+func subscriptionsFromV4(data []SubscriptionV4) []Subscription {
+	subs := make([]Subscription, 0, len(data))
+	for _, s := range data {
+		subs = append(subs, Subscription{
+			ClientID: s.ClientID,
+			Node:     s.Node,
+			Topic:    s.Topic,
+			Qos:      s.Qos,
+		})
+	}
+	return subs
+}
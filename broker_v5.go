@@ -0,0 +1,178 @@
+package emqx
+
+import (
+	"net/http"
+	"time"
+)
+
+type brokerV5 struct {
+	c ClientV5
+}
+
+// NewBrokerV5 adapt a ClientV5 to Broker
+func NewBrokerV5(c ClientV5) Broker {
+	return &brokerV5{c: c}
+}
+
+func (b *brokerV5) APIVersion() APIVersion {
+	return APIVersionV5
+}
+
+func (b *brokerV5) ListNodes() ([]Node, error) {
+	data, err := b.c.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]Node, 0, len(data))
+	for _, n := range data {
+		nodes = append(nodes, Node{
+			Name:        n.Node,
+			Status:      n.NodeStatus,
+			Version:     n.Version,
+			Uptime:      (time.Duration(n.Uptime) * time.Millisecond).String(),
+			Connections: n.Connections,
+		})
+	}
+	return nodes, nil
+}
+
+// listClients walk all pages of clients
+func (b *brokerV5) listClients() ([]ClientInfoV5, error) {
+	clients := []ClientInfoV5{}
+	for page := 1; ; page++ {
+		resp, err := b.c.ListClients(&ListClientsQueryV5{Page: page, Limit: brokerPageLimit})
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, resp.Data...)
+		if !resp.Meta.HasNext || len(resp.Data) == 0 {
+			return clients, nil
+		}
+	}
+}
+
+func (b *brokerV5) ListConnections() ([]Connection, error) {
+	clients, err := b.listClients()
+	if err != nil {
+		return nil, err
+	}
+	conns := make([]Connection, 0, len(clients))
+	for _, c := range clients {
+		conns = append(conns, connectionFromV5(c))
+	}
+	return conns, nil
+}
+
+func (b *brokerV5) GetConnection(clientid string) (*Connection, error) {
+	c, err := b.c.GetClient(clientid)
+	if err != nil {
+		if e, ok := err.(*ErrorResponseV5); ok && e.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	conn := connectionFromV5(*c)
+	return &conn, nil
+}
+
+func (b *brokerV5) ListSessions() ([]Session, error) {
+	clients, err := b.listClients()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, 0, len(clients))
+	for _, c := range clients {
+		sessions = append(sessions, Session{
+			ClientID:           c.ClientID,
+			Username:           c.Username,
+			Node:               c.Node,
+			CleanStart:         c.CleanStart,
+			CreatedAt:          c.CreatedAt,
+			ExpiryInterval:     c.ExpiryInterval,
+			SubscriptionsCount: c.SubscriptionsCnt,
+			InflightLen:        c.InflightCnt,
+			MqueueLen:          c.MqueueLen,
+			MqueueDropped:      c.MqueueDropped,
+		})
+	}
+	return sessions, nil
+}
+
+func (b *brokerV5) ListSubscriptions() ([]Subscription, error) {
+	subs := []Subscription{}
+	for page := 1; ; page++ {
+		resp, err := b.c.ListSubscriptions(&ListSubscriptionsQueryV5{Page: page, Limit: brokerPageLimit})
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, subscriptionsFromV5(resp.Data)...)
+		if !resp.Meta.HasNext || len(resp.Data) == 0 {
+			return subs, nil
+		}
+	}
+}
+
+func (b *brokerV5) ListClientSubscriptions(clientid string) ([]Subscription, error) {
+	data, err := b.c.ListClientSubscriptions(clientid)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptionsFromV5(data), nil
+}
+
+func (b *brokerV5) GetMetrics() (Metrics, error) {
+	data, err := b.c.GetMetrics()
+	if err != nil {
+		return nil, err
+	}
+	return Metrics(data), nil
+}
+
+func (b *brokerV5) GetNodeMetrics(node string) (Metrics, error) {
+	data, err := b.c.GetNodeMetrics(node)
+	if err != nil {
+		return nil, err
+	}
+	return Metrics(data), nil
+}
+
+func (b *brokerV5) Publish(msg *Message) error {
+	_, err := b.c.PublishMessage(&PublishMessageRequestV5{
+		Topic:    msg.Topic,
+		Payload:  msg.Payload,
+		Qos:      msg.Qos,
+		Retain:   msg.Retain,
+		ClientID: msg.ClientID,
+	})
+	return err
+}
+
+func connectionFromV5(c ClientInfoV5) Connection {
+	return Connection{
+		ClientID:    c.ClientID,
+		Username:    c.Username,
+		Node:        c.Node,
+		Connected:   c.Connected,
+		ConnectedAt: c.ConnectedAt,
+		IPAddress:   c.IPAddress,
+		Port:        c.Port,
+		ProtoName:   c.ProtoName,
+		ProtoVer:    c.ProtoVer,
+		KeepAlive:   c.KeepAlive,
+		CleanStart:  c.CleanStart,
+		IsBridge:    c.IsBridge,
+	}
+}
+
+func subscriptionsFromV5(data []SubscriptionV5) []Subscription {
+	subs := make([]Subscription, 0, len(data))
+	for _, s := range data {
+		subs = append(subs, Subscription{
+			ClientID: s.ClientID,
+			Node:     s.Node,
+			Topic:    s.Topic,
+			Qos:      s.Qos,
+		})
+	}
+	return subs
+}
@@ -0,0 +1,384 @@
+package emqx
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache groups, mutations invalidate the reads of their groups
+const (
+	cacheAPI       = "api"
+	cacheCluster   = "cluster"
+	cachePlugins   = "plugins"
+	cacheConfigs   = "configs"
+	cacheListeners = "listeners"
+	cacheApps      = "apps"
+	cacheUsers     = "users"
+)
+
+// CacheConfig caching client config
+type CacheConfig struct {
+	// TTL time a response is cached, default to 5s
+	TTL time.Duration
+	// TTLs per method TTL, by Client method name, e.g. ListClusterPlugins.
+	// A negative TTL disables caching of the method.
+	TTLs map[string]time.Duration
+}
+
+// CachingClient Client caching slow changing reads: cluster, nodes, plugins, configs,
+// listeners, apps and users. Concurrent identical reads share a single request, and
+// mutations made through the client, e.g. StartNodePlugins, invalidate the related reads.
+// Other calls go straight to the wrapped client.
+// Cached responses are shared between callers and must not be modified.
+type CachingClient struct {
+	Client
+	config CacheConfig
+	now    func() time.Time
+	flight flightGroup
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// epoch and generations count invalidations of all groups and of each group
+	epoch       uint64
+	generations map[string]uint64
+}
+
+type cacheEntry struct {
+	group     string
+	val       interface{}
+	expiresAt time.Time
+}
+
+// NewCachingClient wrap c with a cache
+func NewCachingClient(c Client, config CacheConfig) *CachingClient {
+	if config.TTL == 0 {
+		config.TTL = time.Second * 5
+	}
+	return &CachingClient{
+		Client:      c,
+		config:      config,
+		now:         time.Now,
+		entries:     map[string]cacheEntry{},
+		generations: map[string]uint64{},
+	}
+}
+
+// Invalidate drop all cached responses
+func (c *CachingClient) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epoch++
+	c.entries = map[string]cacheEntry{}
+}
+
+// invalidate drop the cached responses of groups
+func (c *CachingClient) invalidate(groups ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, group := range groups {
+		c.generations[group]++
+		for k, e := range c.entries {
+			if e.group == group {
+				delete(c.entries, k)
+			}
+		}
+	}
+}
+
+// generation change on every invalidation of group, c.mu must be held
+func (c *CachingClient) generation(group string) uint64 {
+	return c.epoch + c.generations[group]
+}
+
+func (c *CachingClient) ttl(method string) time.Duration {
+	if ttl, ok := c.config.TTLs[method]; ok {
+		return ttl
+	}
+	return c.config.TTL
+}
+
+// get return the cached response of method with args, or fetch it
+func (c *CachingClient) get(group, method string, fetch func() (interface{}, error), args ...string) (interface{}, error) {
+	ttl := c.ttl(method)
+	if ttl < 0 {
+		return fetch()
+	}
+	key := method + "/" + strings.Join(args, "/")
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.val, nil
+	}
+	generation := c.generation(group)
+	c.mu.Unlock()
+
+	// reads after an invalidation do not join a flight started before it
+	flightKey := key + "#" + strconv.FormatUint(generation, 10)
+	val, err, _ := c.flight.do(context.Background(), flightKey, func() (interface{}, error) {
+		val, err := fetch()
+		if err != nil || !cacheable(val) {
+			return val, err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// skip responses fetched before an invalidation
+		if c.generation(group) == generation {
+			c.entries[key] = cacheEntry{group: group, val: val, expiresAt: c.now().Add(ttl)}
+		}
+		return val, nil
+	})
+	return val, err
+}
+
+// cacheable report whether a response succeeded, EMQX errors have a non zero Code
+func cacheable(val interface{}) bool {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return false
+	}
+	code := v.Elem().FieldByName("Code")
+	return !code.IsValid() || code.Kind() != reflect.Int || code.Int() == 0
+}
+
+// ListAllAPI cached
+func (c *CachingClient) ListAllAPI() (*ListAPIResponseV3, error) {
+	v, err := c.get(cacheAPI, "ListAllAPI", func() (interface{}, error) {
+		return c.Client.ListAllAPI()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListAPIResponseV3), nil
+}
+
+// ListCluster cached
+func (c *CachingClient) ListCluster() (*ListClusterResponseV3, error) {
+	v, err := c.get(cacheCluster, "ListCluster", func() (interface{}, error) {
+		return c.Client.ListCluster()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListClusterResponseV3), nil
+}
+
+// GetNodeInfo cached
+func (c *CachingClient) GetNodeInfo(node string) (*NodeInfoResponseV3, error) {
+	v, err := c.get(cacheCluster, "GetNodeInfo", func() (interface{}, error) {
+		return c.Client.GetNodeInfo(node)
+	}, node)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*NodeInfoResponseV3), nil
+}
+
+// ListClusterPlugins cached
+func (c *CachingClient) ListClusterPlugins() (*ListClusterPluginResponseV3, error) {
+	v, err := c.get(cachePlugins, "ListClusterPlugins", func() (interface{}, error) {
+		return c.Client.ListClusterPlugins()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListClusterPluginResponseV3), nil
+}
+
+// ListNodePlugins cached
+func (c *CachingClient) ListNodePlugins(node string) (*ListNodePluginResponseV3, error) {
+	v, err := c.get(cachePlugins, "ListNodePlugins", func() (interface{}, error) {
+		return c.Client.ListNodePlugins(node)
+	}, node)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListNodePluginResponseV3), nil
+}
+
+// StartNodePlugins invalidate plugins, configs and the API list, plugins serve endpoints
+func (c *CachingClient) StartNodePlugins(node, plugin string) (*NoContentResponse, error) {
+	defer c.invalidate(cachePlugins, cacheConfigs, cacheAPI)
+	return c.Client.StartNodePlugins(node, plugin)
+}
+
+// StopNodePlugins invalidate plugins, configs and the API list
+func (c *CachingClient) StopNodePlugins(node, plugin string) (*NoContentResponse, error) {
+	defer c.invalidate(cachePlugins, cacheConfigs, cacheAPI)
+	return c.Client.StopNodePlugins(node, plugin)
+}
+
+// GetNodePluginConfig cached
+func (c *CachingClient) GetNodePluginConfig(node, plugin string) (*GetPluginConfigResponseV3, error) {
+	v, err := c.get(cachePlugins, "GetNodePluginConfig", func() (interface{}, error) {
+		return c.Client.GetNodePluginConfig(node, plugin)
+	}, node, plugin)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*GetPluginConfigResponseV3), nil
+}
+
+// UpdateNodePluginConfig invalidate plugins and configs
+func (c *CachingClient) UpdateNodePluginConfig(node, plugin string, config map[string]interface{}) (*NoContentResponse, error) {
+	defer c.invalidate(cachePlugins, cacheConfigs)
+	return c.Client.UpdateNodePluginConfig(node, plugin, config)
+}
+
+// GetPluginConfig cached
+func (c *CachingClient) GetPluginConfig(plugin string) (*GetPluginConfigResponseV3, error) {
+	v, err := c.get(cachePlugins, "GetPluginConfig", func() (interface{}, error) {
+		return c.Client.GetPluginConfig(plugin)
+	}, plugin)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*GetPluginConfigResponseV3), nil
+}
+
+// UpdatePluginConfig invalidate plugins and configs
+func (c *CachingClient) UpdatePluginConfig(plugin string, config map[string]interface{}) (*NoContentResponse, error) {
+	defer c.invalidate(cachePlugins, cacheConfigs)
+	return c.Client.UpdatePluginConfig(plugin, config)
+}
+
+// GetConfigs cached
+func (c *CachingClient) GetConfigs() (*ListConfigsResponseV3, error) {
+	v, err := c.get(cacheConfigs, "GetConfigs", func() (interface{}, error) {
+		return c.Client.GetConfigs()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListConfigsResponseV3), nil
+}
+
+// GetNodeConfigs cached
+func (c *CachingClient) GetNodeConfigs(node string) (*GetNodeConfigsResponseV3, error) {
+	v, err := c.get(cacheConfigs, "GetNodeConfigs", func() (interface{}, error) {
+		return c.Client.GetNodeConfigs(node)
+	}, node)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*GetNodeConfigsResponseV3), nil
+}
+
+// UpdateNodeConfig invalidate configs and plugins
+func (c *CachingClient) UpdateNodeConfig(node, app string, config map[string]interface{}) (*NoContentResponse, error) {
+	defer c.invalidate(cacheConfigs, cachePlugins)
+	return c.Client.UpdateNodeConfig(node, app, config)
+}
+
+// ListClusterListeners cached
+func (c *CachingClient) ListClusterListeners() (*ListClusterListenersResponseV3, error) {
+	v, err := c.get(cacheListeners, "ListClusterListeners", func() (interface{}, error) {
+		return c.Client.ListClusterListeners()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListClusterListenersResponseV3), nil
+}
+
+// ListNodeListeners cached
+func (c *CachingClient) ListNodeListeners(node string) (*ListNodeListenerResponseV3, error) {
+	v, err := c.get(cacheListeners, "ListNodeListeners", func() (interface{}, error) {
+		return c.Client.ListNodeListeners(node)
+	}, node)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListNodeListenerResponseV3), nil
+}
+
+// RestartNodeListener invalidate listeners
+func (c *CachingClient) RestartNodeListener(node, listener string) (*NoContentResponse, error) {
+	defer c.invalidate(cacheListeners)
+	return c.Client.RestartNodeListener(node, listener)
+}
+
+// StopNodeListener invalidate listeners
+func (c *CachingClient) StopNodeListener(node, listener string) (*NoContentResponse, error) {
+	defer c.invalidate(cacheListeners)
+	return c.Client.StopNodeListener(node, listener)
+}
+
+// StartNodeListener invalidate listeners
+func (c *CachingClient) StartNodeListener(node, listener string) (*NoContentResponse, error) {
+	defer c.invalidate(cacheListeners)
+	return c.Client.StartNodeListener(node, listener)
+}
+
+// ListApps cached
+func (c *CachingClient) ListApps() (*ListAppsResponseV3, error) {
+	v, err := c.get(cacheApps, "ListApps", func() (interface{}, error) {
+		return c.Client.ListApps()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListAppsResponseV3), nil
+}
+
+// GetApp cached
+func (c *CachingClient) GetApp(appid string) (*GetAppResponseV3, error) {
+	v, err := c.get(cacheApps, "GetApp", func() (interface{}, error) {
+		return c.Client.GetApp(appid)
+	}, appid)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*GetAppResponseV3), nil
+}
+
+// CreateApp invalidate apps
+func (c *CachingClient) CreateApp(req *CreateAppRequestV3) (*CreateAppResponseV3, error) {
+	defer c.invalidate(cacheApps)
+	return c.Client.CreateApp(req)
+}
+
+// UpdateApp invalidate apps
+func (c *CachingClient) UpdateApp(appid string, req *UpdateAppRequestV3) (*NoContentResponse, error) {
+	defer c.invalidate(cacheApps)
+	return c.Client.UpdateApp(appid, req)
+}
+
+// DeleteApp invalidate apps
+func (c *CachingClient) DeleteApp(appid string) (*NoContentResponse, error) {
+	defer c.invalidate(cacheApps)
+	return c.Client.DeleteApp(appid)
+}
+
+// ListUsers cached
+func (c *CachingClient) ListUsers() (*ListUsersResponseV3, error) {
+	v, err := c.get(cacheUsers, "ListUsers", func() (interface{}, error) {
+		return c.Client.ListUsers()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ListUsersResponseV3), nil
+}
+
+// CreateUser invalidate users
+func (c *CachingClient) CreateUser(req *CreateUserRequestV3) (*NoContentResponse, error) {
+	defer c.invalidate(cacheUsers)
+	return c.Client.CreateUser(req)
+}
+
+// UpdateUser invalidate users
+func (c *CachingClient) UpdateUser(username string, req *UpdateUserRequestV3) (*NoContentResponse, error) {
+	defer c.invalidate(cacheUsers)
+	return c.Client.UpdateUser(username, req)
+}
+
+// DeleteUser invalidate users
+func (c *CachingClient) DeleteUser(username string) (*NoContentResponse, error) {
+	defer c.invalidate(cacheUsers)
+	return c.Client.DeleteUser(username)
+}
@@ -0,0 +1,155 @@
+package emqx
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingClient struct {
+	Client
+	plugins int32
+	apis    int32
+	release chan struct{}
+}
+
+func (c *countingClient) ListAllAPI() (*ListAPIResponseV3, error) {
+	atomic.AddInt32(&c.apis, 1)
+	return &ListAPIResponseV3{}, nil
+}
+
+func (c *countingClient) ListClusterPlugins() (*ListClusterPluginResponseV3, error) {
+	atomic.AddInt32(&c.plugins, 1)
+	if c.release != nil {
+		<-c.release
+	}
+	return &ListClusterPluginResponseV3{}, nil
+}
+
+func (c *countingClient) StartNodePlugins(node, plugin string) (*NoContentResponse, error) {
+	return &NoContentResponse{}, nil
+}
+
+func TestCachingClient(t *testing.T) {
+	fake := &countingClient{}
+	c := NewCachingClient(fake, CacheConfig{TTL: time.Minute})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	first, _ := c.ListClusterPlugins()
+	second, _ := c.ListClusterPlugins()
+	if fake.plugins != 1 || first != second {
+		t.Fatalf("expected cached response, %d requests", fake.plugins)
+	}
+
+	c.ListAllAPI()
+	c.StartNodePlugins("n1", PluginWebHook)
+	c.ListClusterPlugins()
+	c.ListAllAPI()
+	if fake.plugins != 2 || fake.apis != 2 {
+		t.Fatalf("expected invalidation, %d plugins and %d api requests", fake.plugins, fake.apis)
+	}
+
+	now = now.Add(time.Minute)
+	c.ListClusterPlugins()
+	if fake.plugins != 3 {
+		t.Fatalf("expected expiry, %d requests", fake.plugins)
+	}
+}
+
+func TestCachingClientDeduplicate(t *testing.T) {
+	fake := &countingClient{release: make(chan struct{})}
+	c := NewCachingClient(fake, CacheConfig{TTLs: map[string]time.Duration{"ListClusterPlugins": -1}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.ListClusterPlugins()
+		}()
+	}
+	// caching is disabled, every call is sent
+	for i := 0; i < 5; i++ {
+		fake.release <- struct{}{}
+	}
+	wg.Wait()
+	if fake.plugins != 5 {
+		t.Fatalf("expected uncached requests, got %d", fake.plugins)
+	}
+
+	c = NewCachingClient(fake, CacheConfig{})
+	fake.plugins = 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.ListClusterPlugins()
+		}()
+	}
+	// let the calls join the flight before releasing it
+	time.Sleep(time.Millisecond * 50)
+	close(fake.release)
+	wg.Wait()
+	if fake.plugins != 1 {
+		t.Fatalf("expected a single request, got %d", fake.plugins)
+	}
+}
+
+func TestCachingClientInvalidateInFlight(t *testing.T) {
+	fake := &countingClient{release: make(chan struct{})}
+	c := NewCachingClient(fake, CacheConfig{})
+
+	var wg sync.WaitGroup
+	read := func() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.ListClusterPlugins()
+		}()
+		// let the call start or join a flight
+		time.Sleep(time.Millisecond * 50)
+	}
+	read()
+	c.StartNodePlugins("n1", PluginWebHook)
+	// must not share the response fetched before the plugin was started
+	read()
+	close(fake.release)
+	wg.Wait()
+	if fake.plugins != 2 {
+		t.Fatalf("expected a request after the invalidation, got %d", fake.plugins)
+	}
+}
+
+func TestCachingClientInvalidateAll(t *testing.T) {
+	fake := &countingClient{release: make(chan struct{})}
+	c := NewCachingClient(fake, CacheConfig{TTL: time.Minute})
+
+	var wg sync.WaitGroup
+	var stale *ListClusterPluginResponseV3
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		stale, _ = c.ListClusterPlugins()
+	}()
+	// let the fetch start before invalidating
+	time.Sleep(time.Millisecond * 50)
+	c.Invalidate()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.ListClusterPlugins()
+	}()
+	time.Sleep(time.Millisecond * 50)
+	close(fake.release)
+	wg.Wait()
+	if fake.plugins != 2 {
+		t.Fatalf("expected a request after the invalidation, got %d", fake.plugins)
+	}
+
+	// the response fetched before the invalidation is not cached
+	if cached, _ := c.ListClusterPlugins(); cached == stale || fake.plugins != 2 {
+		t.Fatalf("unexpected cached response, %d requests", fake.plugins)
+	}
+}
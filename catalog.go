@@ -0,0 +1,265 @@
+package emqx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrUnsupported endpoint is not served by the broker, e.g. the plugin providing it is not loaded
+type ErrUnsupported struct {
+	// Name endpoint name, empty if the request was made by path
+	Name   string
+	Method string
+	Path   string
+}
+
+func (e *ErrUnsupported) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("emqx: endpoint %s is not supported by the broker", e.Name)
+	}
+	return fmt.Sprintf("emqx: endpoint %s %s is not supported by the broker", e.Method, e.Path)
+}
+
+// Catalog endpoints served by the broker, built from the ListAllAPI descriptors
+type Catalog struct {
+	prefix string
+	apis   []APIV3
+	names  map[string]APIV3
+}
+
+// NewCatalog build a catalog from API descriptors listed under prefix, e.g. api/v3/
+func NewCatalog(prefix string, apis []APIV3) *Catalog {
+	c := &Catalog{
+		prefix: strings.Trim(prefix, "/"),
+		apis:   apis,
+		names:  map[string]APIV3{},
+	}
+	for _, api := range apis {
+		c.names[catalogKey(api.Name)] = api
+	}
+	return c
+}
+
+// Supports report whether an endpoint is served, by descriptor name.
+// Names match regardless of word order and separator, so banned.create
+// matches the create_banned descriptor.
+func (c *Catalog) Supports(name string) bool {
+	_, ok := c.names[catalogKey(name)]
+	return ok
+}
+
+// Require return *ErrUnsupported if the endpoint is not served, by descriptor name
+func (c *Catalog) Require(name string) error {
+	if c.Supports(name) {
+		return nil
+	}
+	return &ErrUnsupported{Name: name}
+}
+
+// Get the descriptor of an endpoint by name
+func (c *Catalog) Get(name string) (APIV3, bool) {
+	api, ok := c.names[catalogKey(name)]
+	return api, ok
+}
+
+// Lookup find the descriptor serving a request, endpoint is relative to the base url,
+// e.g. api/v3/nodes/emqx@127.0.0.1/plugins/
+func (c *Catalog) Lookup(method, endpoint string) (APIV3, bool) {
+	path := catalogPath(endpoint)
+	path = strings.TrimPrefix(strings.TrimPrefix(path, c.prefix), "/")
+	for _, api := range c.apis {
+		if strings.EqualFold(api.Method, method) && matchTemplate(catalogPath(api.Path), path) {
+			return api, true
+		}
+	}
+	return APIV3{}, false
+}
+
+// Names list all descriptor names, sorted
+func (c *Catalog) Names() []string {
+	names := make([]string, 0, len(c.apis))
+	for _, api := range c.apis {
+		names = append(names, api.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// covers report whether requests to endpoint are subject to the catalog
+func (c *Catalog) covers(endpoint string) bool {
+	path := catalogPath(endpoint)
+	return strings.HasPrefix(path, c.prefix+"/")
+}
+
+// check fail fast when the catalog does not serve the request
+func (c *Catalog) check(method, endpoint string) error {
+	if !c.covers(endpoint) {
+		return nil
+	}
+	if _, ok := c.Lookup(method, endpoint); ok {
+		return nil
+	}
+	return &ErrUnsupported{Method: method, Path: endpoint}
+}
+
+// catalogKey normalize a name to its sorted words, e.g. banned.create => banned_create
+func catalogKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == '.' || r == '_' || r == '-' || r == ' '
+	})
+	sort.Strings(words)
+	return strings.Join(words, "_")
+}
+
+// catalogPath strip query and surrounding slashes
+func catalogPath(path string) string {
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.Trim(path, "/")
+}
+
+// matchTemplate match a path against a template with :param segments
+func matchTemplate(template, path string) bool {
+	ts := strings.Split(template, "/")
+	ps := strings.Split(path, "/")
+	if len(ts) != len(ps) {
+		return false
+	}
+	for i := range ts {
+		if strings.HasPrefix(ts[i], ":") {
+			if ps[i] == "" {
+				return false
+			}
+			continue
+		}
+		if ts[i] != ps[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadCatalog list the endpoints served by the broker and keep them as catalog.
+// With RequireCatalog, requests to endpoints missing from the catalog fail fast with *ErrUnsupported.
+func (a *APIClient) LoadCatalog() (*Catalog, error) {
+	resp, err := a.ListAllAPI()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	c := NewCatalog("api/v3/", resp.Data)
+	a.setCatalog(c)
+	return c, nil
+}
+
+// Supports report whether the broker serves an endpoint, by descriptor name,
+// loading the catalog on first use. Returns false if the catalog cannot be loaded.
+// It does not enable RequireCatalog.
+func (a *APIClient) Supports(name string) bool {
+	c, err := a.currentCatalog()
+	if err != nil {
+		return false
+	}
+	return c.Supports(name)
+}
+
+// LoadCatalog list the endpoints served by the broker and keep them as catalog.
+// With RequireCatalog, requests to endpoints missing from the catalog fail fast with *ErrUnsupported.
+func (a *APIClientV4) LoadCatalog() (*Catalog, error) {
+	resp, err := a.ListAllAPI()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(resp.Code); err != nil {
+		return nil, err
+	}
+	apis := make([]APIV3, 0, len(resp.Data))
+	for _, api := range resp.Data {
+		apis = append(apis, APIV3(api))
+	}
+	c := NewCatalog("api/v4/", apis)
+	a.setCatalog(c)
+	return c, nil
+}
+
+// Supports report whether the broker serves an endpoint, by descriptor name,
+// loading the catalog on first use. Returns false if the catalog cannot be loaded.
+// It does not enable RequireCatalog.
+func (a *APIClientV4) Supports(name string) bool {
+	c, err := a.currentCatalog()
+	if err != nil {
+		return false
+	}
+	return c.Supports(name)
+}
+
+func (t *transport) setCatalog(c *Catalog) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.catalog = c
+	t.catalogStale = false
+	t.catalogErr = nil
+}
+
+func (t *transport) getCatalog() *Catalog {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.catalog
+}
+
+// invalidateCatalog reload the catalog before its next use, e.g. after a plugin was started
+func (t *transport) invalidateCatalog() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.catalogStale = t.catalog != nil
+}
+
+// catalogRetryDelay time a failed catalog load is reported before loading again
+const catalogRetryDelay = time.Second * 10
+
+// currentCatalog the loaded catalog, loading it if missing or stale.
+// A failed load is returned again for catalogRetryDelay instead of listing the catalog before every request.
+func (t *transport) currentCatalog() (*Catalog, error) {
+	t.mu.Lock()
+	c, stale := t.catalog, t.catalogStale
+	loadErr, failedAt := t.catalogErr, t.catalogFailedAt
+	t.mu.Unlock()
+	if c != nil && !stale {
+		return c, nil
+	}
+	if loadErr != nil && time.Since(failedAt) < catalogRetryDelay {
+		return nil, loadErr
+	}
+	if t.loadCatalog == nil {
+		return nil, errors.New("emqx: the API has no catalog")
+	}
+	c, err := t.loadCatalog()
+	if err != nil {
+		t.mu.Lock()
+		t.catalogErr, t.catalogFailedAt = err, time.Now()
+		t.mu.Unlock()
+		return nil, err
+	}
+	return c, nil
+}
+
+// checkCatalog fail fast for requests the catalog does not serve, if required.
+// loadErr reports the catalog could not be loaded, the request is then not checked.
+func (t *transport) checkCatalog(method, endpoint string) (err, loadErr error) {
+	// the catalog itself is listed at the prefix
+	if !t.requireCatalog || !strings.HasPrefix(catalogPath(endpoint), t.catalogPrefix+"/") {
+		return nil, nil
+	}
+	c, loadErr := t.currentCatalog()
+	if loadErr != nil {
+		// the request reports why the broker could not be reached
+		return nil, loadErr
+	}
+	return c.check(method, endpoint), nil
+}
@@ -0,0 +1,123 @@
+package emqx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	c := NewCatalog("api/v3/", []APIV3{
+		{Name: "create_banned", Method: "POST", Path: "/banned/"},
+		{Name: "load_plugin", Method: "PUT", Path: "/nodes/:node/plugins/:plugin/load"},
+	})
+	if !c.Supports("banned.create") || c.Supports("rule.create") {
+		t.Fatal(c.Names())
+	}
+	api, ok := c.Lookup("PUT", "api/v3/nodes/emqx@127.0.0.1/plugins/emqx_web_hook/load")
+	if !ok || api.Name != "load_plugin" {
+		t.Fatal(api)
+	}
+	if _, ok := c.Lookup("GET", "api/v3/nodes/emqx@127.0.0.1/plugins/emqx_web_hook/load"); ok {
+		t.Fatal("method should not match")
+	}
+}
+
+func TestCatalogFailFast(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"code":0,"data":[{"name":"list_brokers","method":"GET","path":"/brokers/","descr":"A list of brokers in the cluster"}]}`))
+	}))
+	defer server.Close()
+
+	// looking up the catalog does not gate requests
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL})
+	if !a.Supports("list_brokers") {
+		t.Fatal("list_brokers should be supported")
+	}
+	if _, err := a.ListClusterPlugins(); err != nil {
+		t.Fatal(err)
+	}
+
+	requests = 0
+	a = NewAPIClient(ClientConfig{BaseURL: server.URL, RequireCatalog: true})
+	if _, err := a.ListCluster(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := a.ListClusterPlugins()
+	if _, ok := err.(*ErrUnsupported); !ok {
+		t.Fatal(err)
+	}
+	// catalog and brokers
+	if requests != 2 {
+		t.Fatal(requests)
+	}
+}
+
+func TestCatalogReloadAfterPluginChange(t *testing.T) {
+	catalogs := 0
+	loaded := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v3/":
+			catalogs++
+			if loaded {
+				w.Write([]byte(`{"code":0,"data":[{"name":"load_plugin","method":"PUT","path":"/nodes/:node/plugins/:plugin/load"},{"name":"list_banned","method":"GET","path":"/banned/"}]}`))
+				return
+			}
+			w.Write([]byte(`{"code":0,"data":[{"name":"load_plugin","method":"PUT","path":"/nodes/:node/plugins/:plugin/load"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/load"):
+			loaded = true
+			w.Write([]byte(`{"code":0}`))
+		default:
+			w.Write([]byte(`{"code":0,"data":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL, RequireCatalog: true}).(*APIClient)
+	if a.Supports("list_banned") {
+		t.Fatal("list_banned should not be supported before the plugin is loaded")
+	}
+	if _, err := a.StartNodePlugins("emqx@127.0.0.1", PluginAuthHTTP); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Supports("list_banned") {
+		t.Fatal("list_banned should be supported once the plugin is loaded")
+	}
+	if catalogs != 2 {
+		t.Fatal(catalogs)
+	}
+}
+
+func TestCatalogLoadFailure(t *testing.T) {
+	catalogs := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/" {
+			catalogs++
+			w.Write([]byte(`{"code":102,"message":"unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":[]}`))
+	}))
+	defer server.Close()
+
+	var infos []RequestInfo
+	var results []RequestResult
+	c := NewAPIClient(ClientConfig{BaseURL: server.URL, RequireCatalog: true, Instrumentation: recorder{&infos, &results}})
+	for i := 0; i < 3; i++ {
+		if _, err := c.ListCluster(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the failure is remembered instead of listing the catalog before every request
+	if catalogs != 1 {
+		t.Fatalf("catalog listed %d times", catalogs)
+	}
+	last := results[len(results)-1]
+	if last.CatalogErr == nil || last.Err != nil {
+		t.Fatalf("expected the catalog error to be reported, got %+v", last)
+	}
+}
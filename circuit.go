@@ -0,0 +1,278 @@
+package emqx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitState state of a circuit
+type CircuitState int
+
+// Circuit states
+const (
+	// CircuitClosed requests are sent
+	CircuitClosed CircuitState = iota
+	// CircuitOpen requests fail fast with *ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen a single trial request is sent, others fail fast
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// ErrCircuitOpen request not sent because the circuit of its broker or endpoint is open
+type ErrCircuitOpen struct {
+	// Key circuit key, base url without trailing slash, followed by method and endpoint template if per endpoint,
+	// or by the node of node scoped endpoints if per node
+	Key string
+	// Until end of the cooldown
+	Until time.Time
+}
+
+func (e *ErrCircuitOpen) Error() string {
+	return fmt.Sprintf("emqx: circuit %s is open until %s", e.Key, e.Until.Format(time.RFC3339))
+}
+
+// CircuitBreakerConfig circuit breaker config
+type CircuitBreakerConfig struct {
+	// FailureThreshold consecutive failures opening the circuit, default to 5
+	FailureThreshold int
+	// Cooldown time the circuit stays open before a trial request, default to 30s
+	Cooldown time.Duration
+	// PerEndpoint keep a circuit per endpoint of each broker instead of one per broker
+	PerEndpoint bool
+	// PerNode keep a circuit per node for node scoped endpoints, e.g. api/v3/nodes/:node/sessions/,
+	// so a failing node does not open the circuit of the others. With PerEndpoint, per node and endpoint.
+	PerNode bool
+	// OnStateChange called on every state change, e.g. for alerting; it must not block
+	OnStateChange func(key string, from, to CircuitState)
+}
+
+// CircuitBreaker fail fast requests to brokers or endpoints failing consecutively.
+// Network errors and 5xx responses are failures; requests whose context is done are not counted.
+// A CircuitBreaker may be shared by several clients, circuits are keyed by base url.
+// Closed circuits are removed once they succeed, and idle ones once there are many.
+type CircuitBreaker struct {
+	c   CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool
+	usedAt   time.Time
+}
+
+// NewCircuitBreaker create a circuit breaker
+func NewCircuitBreaker(c CircuitBreakerConfig) *CircuitBreaker {
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 5
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = time.Second * 30
+	}
+	return &CircuitBreaker{
+		c:        c,
+		now:      time.Now,
+		circuits: map[string]*circuit{},
+	}
+}
+
+// State state of the circuit with key, see ErrCircuitOpen.Key
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && b.now().Sub(c.openedAt) >= b.c.Cooldown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// Middleware fail fast requests while their circuit is open
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			key := b.key(req)
+			if err := b.allow(key); err != nil {
+				return nil, err
+			}
+
+			resp, err := next(req)
+			switch {
+			case req.Context().Err() != nil:
+				b.release(key)
+			case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+				b.failure(key)
+			default:
+				b.success(key)
+			}
+			return resp, err
+		}
+	}
+}
+
+// baseURLKey context key of the base url of a request, set by the transport
+type baseURLKey struct{}
+
+func withBaseURL(ctx context.Context, baseURL string) context.Context {
+	return context.WithValue(ctx, baseURLKey{}, strings.TrimSuffix(baseURL, "/"))
+}
+
+func (b *CircuitBreaker) key(req *http.Request) string {
+	// clients behind one gateway host may target different clusters by path
+	key, _ := req.Context().Value(baseURLKey{}).(string)
+	if key == "" {
+		key = req.URL.Scheme + "://" + req.URL.Host
+	}
+	if !b.c.PerEndpoint && !b.c.PerNode {
+		return key
+	}
+	path := req.URL.Path
+	if u, err := url.Parse(key); err == nil {
+		path = strings.TrimPrefix(path, u.Path)
+	}
+	info := newRequestInfo(req.Method, strings.TrimPrefix(path, "/"), nil)
+	node := info.Params["node"]
+	if b.c.PerEndpoint {
+		endpoint := info.Endpoint
+		if b.c.PerNode && node != "" {
+			endpoint = strings.Replace(endpoint, ":node", node, 1)
+		}
+		return key + " " + req.Method + " " + endpoint
+	}
+	if node != "" {
+		key += " " + node
+	}
+	return key
+}
+
+// circuitSweepSize circuits kept before idle ones are removed
+const circuitSweepSize = 1024
+
+// allow let the request through, or fail fast
+func (b *CircuitBreaker) allow(key string) error {
+	b.mu.Lock()
+	now := b.now()
+	c, ok := b.circuits[key]
+	if !ok {
+		if len(b.circuits) >= circuitSweepSize {
+			b.sweep(now)
+		}
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	c.usedAt = now
+
+	var err error
+	notify := func() {}
+	switch c.state {
+	case CircuitOpen:
+		if now.Sub(c.openedAt) < b.c.Cooldown {
+			err = &ErrCircuitOpen{Key: key, Until: c.openedAt.Add(b.c.Cooldown)}
+			break
+		}
+		notify = b.setState(key, c, CircuitHalfOpen)
+		c.trial = true
+	case CircuitHalfOpen:
+		if c.trial {
+			err = &ErrCircuitOpen{Key: key, Until: c.openedAt.Add(b.c.Cooldown)}
+			break
+		}
+		c.trial = true
+	}
+	b.mu.Unlock()
+	notify()
+	return err
+}
+
+// sweep remove circuits not used for a cooldown, b.mu must be held
+func (b *CircuitBreaker) sweep(now time.Time) {
+	for key, c := range b.circuits {
+		if !c.trial && now.Sub(c.usedAt) >= b.c.Cooldown {
+			delete(b.circuits, key)
+		}
+	}
+}
+
+func (b *CircuitBreaker) success(key string) {
+	b.mu.Lock()
+	c, ok := b.circuits[key]
+	if !ok {
+		b.mu.Unlock()
+		return
+	}
+	notify := b.setState(key, c, CircuitClosed)
+	// a closed circuit without failures is the same as none
+	delete(b.circuits, key)
+	b.mu.Unlock()
+	notify()
+}
+
+func (b *CircuitBreaker) failure(key string) {
+	b.mu.Lock()
+	c, ok := b.circuits[key]
+	if !ok {
+		// removed by a concurrent success
+		c = &circuit{usedAt: b.now()}
+		b.circuits[key] = c
+	}
+	c.failures++
+	c.trial = false
+	notify := func() {}
+	if c.state == CircuitHalfOpen || c.failures >= b.c.FailureThreshold {
+		c.openedAt = b.now()
+		notify = b.setState(key, c, CircuitOpen)
+	}
+	b.mu.Unlock()
+	notify()
+}
+
+// release end a request without outcome, e.g. canceled by the caller
+func (b *CircuitBreaker) release(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[key]; ok {
+		c.trial = false
+	}
+}
+
+// setState change the state of c, b.mu must be held.
+// The returned func calls OnStateChange, once b.mu is released.
+func (b *CircuitBreaker) setState(key string, c *circuit, state CircuitState) func() {
+	if c.state == state {
+		return func() {}
+	}
+	from := c.state
+	c.state = state
+	if b.c.OnStateChange == nil {
+		return func() {}
+	}
+	return func() {
+		b.c.OnStateChange(key, from, state)
+	}
+}
@@ -0,0 +1,204 @@
+package emqx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var requests, failing int32 = 0, 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":[]}`))
+	}))
+	defer server.Close()
+
+	var changes []string
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+		OnStateChange: func(key string, from, to CircuitState) {
+			changes = append(changes, from.String()+">"+to.String())
+		},
+	})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	c := NewAPIClient(ClientConfig{BaseURL: server.URL, CircuitBreaker: breaker})
+	c.ListNodeStats()
+	c.ListNodeStats()
+	_, err := c.ListNodeStats()
+	if _, ok := err.(*ErrCircuitOpen); !ok {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("%d requests sent while open", requests)
+	}
+	if state := breaker.State(server.URL); state != CircuitOpen {
+		t.Fatalf("unexpected state %s", state)
+	}
+
+	// trial request fails, open again
+	now = now.Add(time.Minute)
+	c.ListNodeStats()
+	if _, err := c.ListNodeStats(); err == nil || requests != 3 {
+		t.Fatalf("expected open circuit after failed trial, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	atomic.StoreInt32(&failing, 0)
+	if _, err := c.ListNodeStats(); err != nil {
+		t.Fatal(err)
+	}
+	if state := breaker.State(server.URL); state != CircuitClosed {
+		t.Fatalf("unexpected state %s", state)
+	}
+
+	want := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("unexpected state changes %v", changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("unexpected state changes %v", changes)
+		}
+	}
+}
+
+func TestCircuitBreakerPerEndpoint(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{PerEndpoint: true})
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v3/nodes/n1/sessions/", nil)
+	if key := breaker.key(req); key != "http://localhost:8080 GET api/v3/nodes/:node/sessions/" {
+		t.Fatal(key)
+	}
+}
+
+func TestCircuitBreakerPerNode(t *testing.T) {
+	for _, c := range []struct {
+		config CircuitBreakerConfig
+		path   string
+		want   string
+	}{
+		{CircuitBreakerConfig{PerNode: true}, "api/v3/nodes/n1/sessions/", "http://localhost:8080 n1"},
+		{CircuitBreakerConfig{PerNode: true}, "api/v3/nodes/n2/sessions/", "http://localhost:8080 n2"},
+		{CircuitBreakerConfig{PerNode: true}, "api/v3/brokers/", "http://localhost:8080"},
+		{CircuitBreakerConfig{PerNode: true, PerEndpoint: true}, "api/v3/nodes/n1/sessions/", "http://localhost:8080 GET api/v3/nodes/n1/sessions/"},
+	} {
+		breaker := NewCircuitBreaker(c.config)
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/"+c.path, nil)
+		if key := breaker.key(req); key != c.want {
+			t.Errorf("%s: got %q, want %q", c.path, key, c.want)
+		}
+	}
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{PerNode: true, FailureThreshold: 1})
+	send := breaker.Middleware()(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/n1/") {
+			return &http.Response{StatusCode: http.StatusInternalServerError}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	request := func(node string) error {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v3/nodes/"+node+"/sessions/", nil)
+		_, err := send(req)
+		return err
+	}
+	request("n1")
+	if _, ok := request("n1").(*ErrCircuitOpen); !ok {
+		t.Fatal("expected the circuit of n1 to be open")
+	}
+	if err := request("n2"); err != nil {
+		t.Fatal(err)
+	}
+	if breaker.State("http://localhost:8080 n1") != CircuitOpen || breaker.State("http://localhost:8080 n2") != CircuitClosed {
+		t.Fatal("a failing node should not open the circuit of the others")
+	}
+}
+
+func TestCircuitBreakerBasePath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/c1/") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":[]}`))
+	}))
+	defer server.Close()
+
+	// two clusters behind one gateway
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1})
+	c1 := NewAPIClient(ClientConfig{BaseURL: server.URL + "/c1", CircuitBreaker: breaker})
+	c2 := NewAPIClient(ClientConfig{BaseURL: server.URL + "/c2/", CircuitBreaker: breaker})
+	c1.ListNodeStats()
+	if _, err := c1.ListNodeStats(); err == nil {
+		t.Fatal("expected the circuit of c1 to be open")
+	}
+	if _, err := c2.ListNodeStats(); err != nil {
+		t.Fatal(err)
+	}
+	if breaker.State(server.URL+"/c1") != CircuitOpen || breaker.State(server.URL+"/c2") != CircuitClosed {
+		t.Fatal("clusters behind one host should not share a circuit")
+	}
+}
+
+func TestCircuitBreakerPrune(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{PerNode: true, FailureThreshold: 1, Cooldown: time.Minute})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	send := breaker.Middleware()(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/nodes/f") {
+			return &http.Response{StatusCode: http.StatusInternalServerError}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	for i := 0; i < circuitSweepSize; i++ {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:8080/api/v3/nodes/n%d/sessions/", i), nil)
+		send(req)
+	}
+	if len(breaker.circuits) != 0 {
+		t.Fatalf("%d closed circuits kept", len(breaker.circuits))
+	}
+
+	for i := 0; i < circuitSweepSize; i++ {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:8080/api/v3/nodes/f%d/sessions/", i), nil)
+		send(req)
+	}
+	if len(breaker.circuits) != circuitSweepSize {
+		t.Fatalf("%d open circuits kept", len(breaker.circuits))
+	}
+	now = now.Add(time.Minute)
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v3/nodes/n1/sessions/", nil)
+	send(req)
+	if len(breaker.circuits) > 1 {
+		t.Fatalf("%d idle circuits kept", len(breaker.circuits))
+	}
+}
+
+func TestCircuitBreakerStateChangeCallback(t *testing.T) {
+	var breaker *CircuitBreaker
+	var states []CircuitState
+	breaker = NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OnStateChange: func(key string, from, to CircuitState) {
+			// the breaker is not locked while notifying
+			states = append(states, breaker.State(key))
+		},
+	})
+	send := breaker.Middleware()(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusInternalServerError}, nil
+	})
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v3/brokers/", nil)
+	send(req)
+	if len(states) != 1 || states[0] != CircuitOpen {
+		t.Fatal(states)
+	}
+}
@@ -2,6 +2,9 @@ package emqx
 
 // Client EMQX API client
 type Client interface {
+	CredentialsSetter
+	Requester
+
 	// List all API describe
 	// GET api/v3/
 	ListAllAPI() (*ListAPIResponseV3, error)
@@ -38,6 +41,34 @@ type Client interface {
 	// GET api/v3/connections/${clientid}
 	GetNodeConnection(clientid string) (*NodeConnectionResponseV3, error)
 
+	// List Connections of a Username in the Cluster
+	// GET api/v3/connections/username/${username}
+	ListConnectionsByUsername(username string) (*ListConnectionsByUsernameResponseV3, error)
+
+	// List Connections of a Username on a node
+	// GET api/v3/nodes/${node}/connections/username/${username}
+	ListNodeConnectionsByUsername(node, username string) (*ListNodeConnectionsByUsernameResponseV3, error)
+
+	// ListClusterConnACLCache List ACL cache of a Connection in the Cluster
+	// GET api/v3/connections/${clientid}/acl_cache
+	ListClusterConnACLCache(clientid string) (*ListACLCacheResponseV3, error)
+
+	// ListNodeConnACLCache List ACL cache of a Connection on a node
+	// GET api/v3/nodes/${node}/connections/${clientid}/acl_cache
+	ListNodeConnACLCache(node, clientid string) (*ListACLCacheResponseV3, error)
+
+	// ClearClusterConnACLCache Clear ACL cache of a Connection in the Cluster
+	// DELETE api/v3/connections/${clientid}/acl_cache
+	ClearClusterConnACLCache(clientid string) (*NoContentResponse, error)
+
+	// ClearNodeConnACLCache Clear ACL cache of a Connection on a node
+	// DELETE api/v3/nodes/${node}/connections/${clientid}/acl_cache
+	ClearNodeConnACLCache(node, clientid string) (*NoContentResponse, error)
+
+	// ClearClusterACLCache Clear ACL cache of all Connections in the Cluster
+	// DELETE api/v3/acl_cache
+	ClearClusterACLCache() (*NoContentResponse, error)
+
 	// List all Sessions in the Cluster
 	// GET api/v3/sessions/
 	ListClusterSessions() (*ListClusterSessionsResponseV3, error)
@@ -54,6 +85,14 @@ type Client interface {
 	// GET api/v3/nodes/${node}/sessions/${clientid}
 	GetNodeSession(node, clientid string) (*GetNodeSessionResponseV3, error)
 
+	// List Sessions of a Username in the Cluster
+	// GET api/v3/sessions/username/${username}
+	ListSessionsByUsername(username string) (*ListSessionsByUsernameResponseV3, error)
+
+	// List Sessions of a Username on a Node
+	// GET api/v3/nodes/${node}/sessions/username/${username}
+	ListNodeSessionsByUsername(node, username string) (*ListNodeSessionsByUsernameResponseV3, error)
+
 	// List all Subscriptions in the Cluster
 	// GET api/v3/subscriptions/
 	ListClusterSubscriptions() (*ListClusterSubscriptionsResponseV3, error)
@@ -106,6 +145,34 @@ type Client interface {
 	// PUT api/v3/nodes/${node}/plugins/${plugin}/unload
 	StopNodePlugins(node, plugin string) (*NoContentResponse, error)
 
+	// GetNodePluginConfig get config of a plugin in a node
+	// GET api/v3/nodes/${node}/plugins/${plugin}
+	GetNodePluginConfig(node, plugin string) (*GetPluginConfigResponseV3, error)
+
+	// UpdateNodePluginConfig update config of a plugin in a node
+	// PUT api/v3/nodes/${node}/plugins/${plugin}
+	UpdateNodePluginConfig(node, plugin string, config map[string]interface{}) (*NoContentResponse, error)
+
+	// GetPluginConfig get config of a plugin in the cluster
+	// GET api/v3/configs/${plugin}
+	GetPluginConfig(plugin string) (*GetPluginConfigResponseV3, error)
+
+	// UpdatePluginConfig update config of a plugin in the cluster
+	// PUT api/v3/configs/${plugin}
+	UpdatePluginConfig(plugin string, config map[string]interface{}) (*NoContentResponse, error)
+
+	// GetConfigs List all configs of Cluster
+	// GET api/v3/configs/
+	GetConfigs() (*ListConfigsResponseV3, error)
+
+	// GetNodeConfigs List all configs in a node
+	// GET api/v3/nodes/${node}/configs/
+	GetNodeConfigs(node string) (*GetNodeConfigsResponseV3, error)
+
+	// UpdateNodeConfig update configs of an app in a node
+	// PUT api/v3/nodes/${node}/configs/${app}
+	UpdateNodeConfig(node, app string, config map[string]interface{}) (*NoContentResponse, error)
+
 	// ListClusterListeners List all listeners of Cluster
 	// GET api/v3/listeners/
 	ListClusterListeners() (*ListClusterListenersResponseV3, error)
@@ -114,6 +181,18 @@ type Client interface {
 	// GET api/v3/nodes/${node}/plugins/
 	ListNodeListeners(node string) (*ListNodeListenerResponseV3, error)
 
+	// RestartNodeListener restart a listener in a node, by listener ID or ListenOn
+	// PUT api/v3/nodes/${node}/listeners/${listener}/restart
+	RestartNodeListener(node, listener string) (*NoContentResponse, error)
+
+	// StopNodeListener stop a listener in a node, by listener ID or ListenOn
+	// PUT api/v3/nodes/${node}/listeners/${listener}/stop
+	StopNodeListener(node, listener string) (*NoContentResponse, error)
+
+	// StartNodeListener start a listener in a node, by listener ID or ListenOn
+	// PUT api/v3/nodes/${node}/listeners/${listener}/start
+	StartNodeListener(node, listener string) (*NoContentResponse, error)
+
 	// ListClusterMetrics List all metrics of Cluster
 	// GET api/v3/metrics/
 	ListClusterMetrics() (*ListClusterMetricsResponseV3, error)
@@ -121,4 +200,66 @@ type Client interface {
 	// GetNodeMetrics get all metrics in a node
 	// GET api/v3/nodes/${node}/metrics/
 	GetNodeMetrics(node string) (*GetNodeMetricsResponseV3, error)
+
+	// ListClusterStats List statistics of all nodes, e.g. connections/count
+	// GET api/v3/stats/
+	ListClusterStats() (*ListClusterStatsResponseV3, error)
+
+	// ListClusterAlarms List present alarms of all nodes
+	// GET api/v3/alarms/present
+	ListClusterAlarms() (*ListClusterAlarmsResponseV3, error)
+
+	// ListNodeAlarms List present alarms of a node
+	// GET api/v3/alarms/present/${node}
+	ListNodeAlarms(node string) (*ListNodeAlarmsResponseV3, error)
+
+	// ListApps List all applications
+	// GET api/v3/apps/
+	ListApps() (*ListAppsResponseV3, error)
+
+	// GetApp Retrieve an application
+	// GET api/v3/apps/${appid}
+	GetApp(appid string) (*GetAppResponseV3, error)
+
+	// CreateApp create an application
+	// POST api/v3/apps/
+	CreateApp(req *CreateAppRequestV3) (*CreateAppResponseV3, error)
+
+	// UpdateApp update an application
+	// PUT api/v3/apps/${appid}
+	UpdateApp(appid string, req *UpdateAppRequestV3) (*NoContentResponse, error)
+
+	// DeleteApp delete an application
+	// DELETE api/v3/apps/${appid}
+	DeleteApp(appid string) (*NoContentResponse, error)
+
+	// AuthUser authenticate a dashboard user
+	// POST api/v3/auth
+	AuthUser(req *AuthUserRequestV3) (*NoContentResponse, error)
+
+	// ListUsers List all dashboard users
+	// GET api/v3/users/
+	ListUsers() (*ListUsersResponseV3, error)
+
+	// CreateUser create a dashboard user
+	// POST api/v3/users/
+	CreateUser(req *CreateUserRequestV3) (*NoContentResponse, error)
+
+	// UpdateUser update a dashboard user
+	// PUT api/v3/users/${username}
+	UpdateUser(username string, req *UpdateUserRequestV3) (*NoContentResponse, error)
+
+	// DeleteUser delete a dashboard user
+	// DELETE api/v3/users/${username}
+	DeleteUser(username string) (*NoContentResponse, error)
+
+	// ChangeUserPassword change password of a dashboard user
+	// PUT api/v3/change_pwd/${username}
+	ChangeUserPassword(username string, req *ChangeUserPasswordRequestV3) (*NoContentResponse, error)
+
+	// LoadCatalog list the endpoints served by the broker and keep them as catalog
+	LoadCatalog() (*Catalog, error)
+
+	// Supports report whether the broker serves an endpoint, by descriptor name
+	Supports(name string) bool
 }
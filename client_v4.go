@@ -0,0 +1,133 @@
+package emqx
+
+// ClientV4 EMQX v4 API client
+type ClientV4 interface {
+	CredentialsSetter
+	Requester
+
+	// List all API describe
+	// GET api/v4/
+	ListAllAPI() (*ListAPIResponseV4, error)
+
+	// List all Cluster
+	// GET api/v4/brokers/
+	ListCluster() (*ListClusterResponseV4, error)
+
+	// Retrieve Info of a Node
+	// GET api/v4/brokers/${node}
+	GetNodeInfo(node string) (*NodeInfoResponseV4, error)
+
+	// List Statistics of All Nodes in the Cluster
+	// GET api/v4/nodes/
+	ListNodeStats() (*ListNodeStatResponseV4, error)
+
+	// Retrieve Statistics of a Specific Node
+	// GET api/v4/nodes/${node}
+	GetNodeStat(node string) (*NodeStatResponseV4, error)
+
+	// List all Clients in the Cluster
+	// GET api/v4/clients
+	ListClients(page *PageV4) (*ListClientsResponseV4, error)
+
+	// List all Clients in a node
+	// GET api/v4/nodes/${node}/clients
+	ListNodeClients(node string, page *PageV4) (*ListClientsResponseV4, error)
+
+	// Retrieve a Client in the Cluster
+	// GET api/v4/clients/${clientid}
+	GetClient(clientid string) (*GetClientResponseV4, error)
+
+	// Retrieve a Client on a node
+	// GET api/v4/nodes/${node}/clients/${clientid}
+	GetNodeClient(node, clientid string) (*GetClientResponseV4, error)
+
+	// List Clients of a Username in the Cluster
+	// GET api/v4/clients/username/${username}
+	ListClientsByUsername(username string) (*GetClientResponseV4, error)
+
+	// Kick a Client in the Cluster
+	// DELETE api/v4/clients/${clientid}
+	KickClient(clientid string) (*NoContentResponse, error)
+
+	// List ACL cache of a Client
+	// GET api/v4/clients/${clientid}/acl_cache
+	ListClientACLCache(clientid string) (*ListACLCacheResponseV4, error)
+
+	// Clear ACL cache of a Client
+	// DELETE api/v4/clients/${clientid}/acl_cache
+	ClearClientACLCache(clientid string) (*NoContentResponse, error)
+
+	// List all Subscriptions in the Cluster
+	// GET api/v4/subscriptions
+	ListSubscriptions(page *PageV4) (*ListSubscriptionsResponseV4, error)
+
+	// List all Subscriptions in a node
+	// GET api/v4/nodes/${node}/subscriptions
+	ListNodeSubscriptions(node string, page *PageV4) (*ListSubscriptionsResponseV4, error)
+
+	// List Subscriptions of a Client in the Cluster
+	// GET api/v4/subscriptions/${clientid}
+	ListClientSubscriptions(clientid string) (*ListClientSubscriptionsResponseV4, error)
+
+	// List all Routes in the Cluster
+	// GET api/v4/routes
+	ListRoutes(page *PageV4) (*ListRoutesResponseV4, error)
+
+	// Retrieve a Route of Topic in the Cluster
+	// GET api/v4/routes/${topic}
+	GetTopicRoute(topic string) (*GetTopicRoutesResponseV4, error)
+
+	// Publish message request
+	// POST api/v4/mqtt/publish
+	PublishMessage(req *PublishMessageRequestV4) (*NoContentResponse, error)
+
+	// create subscription
+	// POST api/v4/mqtt/subscribe
+	CreateSubscription(req *CreateSubscriptionRequestV4) (*NoContentResponse, error)
+
+	// unsubscribe
+	// POST api/v4/mqtt/unsubscribe
+	Unsubscribe(req *UnSubscribeRequestV4) (*NoContentResponse, error)
+
+	// ListClusterPlugins List all Plugins of Cluster
+	// GET api/v4/plugins/
+	ListClusterPlugins() (*ListClusterPluginResponseV4, error)
+
+	// ListNodePlugins List all plugins in a node
+	// GET api/v4/nodes/${node}/plugins/
+	ListNodePlugins(node string) (*ListNodePluginResponseV4, error)
+
+	// StartNodePlugins Start a plugin
+	// PUT api/v4/nodes/${node}/plugins/${plugin}/load
+	StartNodePlugins(node, plugin string) (*NoContentResponse, error)
+
+	// StopNodePlugins stop a plugin
+	// PUT api/v4/nodes/${node}/plugins/${plugin}/unload
+	StopNodePlugins(node, plugin string) (*NoContentResponse, error)
+
+	// ListClusterListeners List all listeners of Cluster
+	// GET api/v4/listeners/
+	ListClusterListeners() (*ListClusterListenersResponseV4, error)
+
+	// ListNodeListeners List all listeners in a node
+	// GET api/v4/nodes/${node}/listeners/
+	ListNodeListeners(node string) (*ListNodeListenerResponseV4, error)
+
+	// RestartNodeListener restart a listener in a node, by listener identifier
+	// PUT api/v4/nodes/${node}/listeners/${identifier}/restart
+	RestartNodeListener(node, identifier string) (*NoContentResponse, error)
+
+	// ListClusterMetrics List all metrics of Cluster
+	// GET api/v4/metrics/
+	ListClusterMetrics() (*ListClusterMetricsResponseV4, error)
+
+	// GetNodeMetrics get all metrics in a node
+	// GET api/v4/nodes/${node}/metrics/
+	GetNodeMetrics(node string) (*GetNodeMetricsResponseV4, error)
+
+	// LoadCatalog list the endpoints served by the broker and keep them as catalog
+	LoadCatalog() (*Catalog, error)
+
+	// Supports report whether the broker serves an endpoint, by descriptor name
+	Supports(name string) bool
+}
@@ -0,0 +1,83 @@
+package emqx
+
+// ClientV5 EMQX v5 API client
+type ClientV5 interface {
+	CredentialsSetter
+	Requester
+
+	// List all Nodes in the Cluster
+	// GET api/v5/nodes
+	ListNodes() ([]NodeV5, error)
+
+	// Retrieve Info of a Node
+	// GET api/v5/nodes/${node}
+	GetNode(node string) (*NodeV5, error)
+
+	// Get aggregated Statistics of the Cluster
+	// GET api/v5/stats?aggregate=true
+	GetStats() (StatsV5, error)
+
+	// Get Statistics of a Node
+	// GET api/v5/nodes/${node}/stats
+	GetNodeStats(node string) (StatsV5, error)
+
+	// Get aggregated Metrics of the Cluster
+	// GET api/v5/metrics?aggregate=true
+	GetMetrics() (MetricsV5, error)
+
+	// Get Metrics of a Node
+	// GET api/v5/nodes/${node}/metrics
+	GetNodeMetrics(node string) (MetricsV5, error)
+
+	// List Clients in the Cluster
+	// GET api/v5/clients
+	ListClients(query *ListClientsQueryV5) (*ListClientsResponseV5, error)
+
+	// Retrieve a Client in the Cluster
+	// GET api/v5/clients/${clientid}
+	GetClient(clientid string) (*ClientInfoV5, error)
+
+	// Kick a Client in the Cluster
+	// DELETE api/v5/clients/${clientid}
+	KickClient(clientid string) (*NoContentResponse, error)
+
+	// List Subscriptions of a Client
+	// GET api/v5/clients/${clientid}/subscriptions
+	ListClientSubscriptions(clientid string) ([]SubscriptionV5, error)
+
+	// Subscribe a Client to a topic
+	// POST api/v5/clients/${clientid}/subscribe
+	Subscribe(clientid string, req *SubscribeRequestV5) (*SubscriptionV5, error)
+
+	// Unsubscribe a Client from a topic
+	// POST api/v5/clients/${clientid}/unsubscribe
+	Unsubscribe(clientid string, req *UnsubscribeRequestV5) (*NoContentResponse, error)
+
+	// List Subscriptions in the Cluster
+	// GET api/v5/subscriptions
+	ListSubscriptions(query *ListSubscriptionsQueryV5) (*ListSubscriptionsResponseV5, error)
+
+	// Publish message request
+	// POST api/v5/publish
+	PublishMessage(req *PublishMessageRequestV5) (*PublishMessageResponseV5, error)
+
+	// List all authentication sources of the global chain
+	// GET api/v5/authentication
+	ListAuthenticationSources() ([]AuthenticationSourceV5, error)
+
+	// Retrieve an authentication source
+	// GET api/v5/authentication/${id}
+	GetAuthenticationSource(id string) (AuthenticationSourceV5, error)
+
+	// Create an authentication source
+	// POST api/v5/authentication
+	CreateAuthenticationSource(source AuthenticationSourceV5) (AuthenticationSourceV5, error)
+
+	// Update an authentication source
+	// PUT api/v5/authentication/${id}
+	UpdateAuthenticationSource(id string, source AuthenticationSourceV5) (AuthenticationSourceV5, error)
+
+	// Delete an authentication source
+	// DELETE api/v5/authentication/${id}
+	DeleteAuthenticationSource(id string) (*NoContentResponse, error)
+}
@@ -0,0 +1,64 @@
+package emqx
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ConfigChangeType kind of a config change
+type ConfigChangeType string
+
+// Config change types
+const (
+	ConfigAdded   ConfigChangeType = "added"
+	ConfigChanged ConfigChangeType = "changed"
+)
+
+// ConfigChange a single difference between current and desired config
+type ConfigChange struct {
+	Key  string
+	Type ConfigChangeType
+	// Old current value, nil if the key is not set
+	Old interface{}
+	// New desired value
+	New interface{}
+}
+
+func (c ConfigChange) String() string {
+	if c.Type == ConfigAdded {
+		return fmt.Sprintf("+ %s = %v", c.Key, c.New)
+	}
+	return fmt.Sprintf("~ %s: %v => %v", c.Key, c.Old, c.New)
+}
+
+// DiffConfig compare the desired config to the current one.
+// Only keys present in desired are considered, since an update never removes keys.
+// Values are compared by their textual form, as EMQX reports most values as strings.
+// Changes are sorted by key.
+func DiffConfig(current, desired map[string]interface{}) []ConfigChange {
+	changes := []ConfigChange{}
+	for k, v := range desired {
+		old, ok := current[k]
+		if !ok {
+			changes = append(changes, ConfigChange{Key: k, Type: ConfigAdded, New: v})
+			continue
+		}
+		if fmt.Sprint(old) != fmt.Sprint(v) {
+			changes = append(changes, ConfigChange{Key: k, Type: ConfigChanged, Old: old, New: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// PreviewNodeConfigUpdate fetch the configs of a node and diff the desired config against them,
+// without applying anything
+func PreviewNodeConfigUpdate(c Client, node string, desired map[string]interface{}) ([]ConfigChange, error) {
+	resp, err := c.GetNodeConfigs(node)
+	if err != nil {
+		return nil, err
+	}
+	return DiffConfig(resp.Map(), desired), nil
+}
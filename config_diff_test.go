@@ -0,0 +1,28 @@
+package emqx
+
+import (
+	"testing"
+)
+
+func TestDiffConfig(t *testing.T) {
+	current := map[string]interface{}{
+		"mqtt.max_packet_size":       "1MB",
+		"zone.external.idle_timeout": "15s",
+		"listener.tcp.external":      "0.0.0.0:1883",
+	}
+	desired := map[string]interface{}{
+		"mqtt.max_packet_size":       "1MB",
+		"zone.external.idle_timeout": "30s",
+		"zone.external.max_inflight": 32,
+	}
+	changes := DiffConfig(current, desired)
+	if len(changes) != 2 {
+		t.Fatal(changes)
+	}
+	if changes[0].Key != "zone.external.idle_timeout" || changes[0].Type != ConfigChanged || changes[0].Old != "15s" {
+		t.Fatal(changes[0])
+	}
+	if changes[1].Key != "zone.external.max_inflight" || changes[1].Type != ConfigAdded {
+		t.Fatal(changes[1])
+	}
+}
@@ -0,0 +1,112 @@
+package emqx
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider provide EMQX application credentials.
+// Credentials is called before every request, so implementations should be cheap
+// and safe for concurrent use.
+type CredentialsProvider interface {
+	Credentials() (appID, appSecret string, err error)
+}
+
+// CredentialsSetter replace the authentication of a live client, implemented by the
+// clients of all API versions, so long-running services pick up rotated secrets
+type CredentialsSetter interface {
+	// SetCredentials replace the application credentials used by subsequent requests
+	SetCredentials(appID, appSecret string)
+	// SetCredentialsProvider replace the credentials provider used by subsequent requests
+	SetCredentialsProvider(p CredentialsProvider)
+	// SetAuthenticator replace the authenticator used by subsequent requests
+	SetAuthenticator(auth Authenticator)
+}
+
+// StaticCredentials fixed application credentials
+type StaticCredentials struct {
+	AppID     string
+	AppSecret string
+}
+
+// Credentials return the fixed credentials
+func (c StaticCredentials) Credentials() (string, string, error) {
+	return c.AppID, c.AppSecret, nil
+}
+
+// EnvCredentials read application credentials from environment variables on each call
+type EnvCredentials struct {
+	// AppIDVar environment variable of the application ID, default to EMQX_APP_ID
+	AppIDVar string
+	// AppSecretVar environment variable of the application secret, default to EMQX_APP_SECRET
+	AppSecretVar string
+}
+
+// Credentials read the credentials from environment variables
+func (c EnvCredentials) Credentials() (string, string, error) {
+	idVar, secretVar := c.AppIDVar, c.AppSecretVar
+	if idVar == "" {
+		idVar = "EMQX_APP_ID"
+	}
+	if secretVar == "" {
+		secretVar = "EMQX_APP_SECRET"
+	}
+	return os.Getenv(idVar), os.Getenv(secretVar), nil
+}
+
+// FileCredentials read application credentials from files, e.g. mounted secrets.
+// The files are read again only when their modification time changes.
+type FileCredentials struct {
+	appIDPath     string
+	appSecretPath string
+
+	mu           sync.Mutex
+	appID        string
+	appSecret    string
+	appIDMod     time.Time
+	appSecretMod time.Time
+}
+
+// NewFileCredentials create a provider reading the application ID and secret from two files.
+// Surrounding whitespace in the files is ignored.
+func NewFileCredentials(appIDPath, appSecretPath string) *FileCredentials {
+	return &FileCredentials{
+		appIDPath:     appIDPath,
+		appSecretPath: appSecretPath,
+	}
+}
+
+// Credentials return the credentials, reloading the files if they changed
+func (c *FileCredentials) Credentials() (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := reloadFile(c.appIDPath, &c.appID, &c.appIDMod); err != nil {
+		return "", "", err
+	}
+	if err := reloadFile(c.appSecretPath, &c.appSecret, &c.appSecretMod); err != nil {
+		return "", "", err
+	}
+	return c.appID, c.appSecret, nil
+}
+
+func reloadFile(path string, value *string, modTime *time.Time) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(*modTime) {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	*value = strings.TrimSpace(string(content))
+	*modTime = info.ModTime()
+	return nil
+}
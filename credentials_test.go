@@ -0,0 +1,69 @@
+package emqx
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetCredentials(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer server.Close()
+
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL, AppID: "old", AppSecret: "secret"})
+	if _, err := a.ListCluster(); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Basic b2xkOnNlY3JldA==" {
+		t.Fatal(auth)
+	}
+
+	a.SetCredentials("new", "secret")
+	if _, err := a.ListCluster(); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Basic bmV3OnNlY3JldA==" {
+		t.Fatal(auth)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emqx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idPath := filepath.Join(dir, "app_id")
+	secretPath := filepath.Join(dir, "app_secret")
+	ioutil.WriteFile(idPath, []byte("app\n"), 0600)
+	ioutil.WriteFile(secretPath, []byte("secret1\n"), 0600)
+
+	c := NewFileCredentials(idPath, secretPath)
+	id, secret, err := c.Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "app" || secret != "secret1" {
+		t.Fatal(id, secret)
+	}
+
+	ioutil.WriteFile(secretPath, []byte("secret2"), 0600)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(secretPath, later, later)
+	_, secret, err = c.Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != "secret2" {
+		t.Fatal(secret)
+	}
+}
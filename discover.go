@@ -0,0 +1,193 @@
+package emqx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIVersion EMQX REST API version
+type APIVersion string
+
+// Supported API versions
+const (
+	APIVersionV3 APIVersion = "v3"
+	APIVersionV4 APIVersion = "v4"
+	APIVersionV5 APIVersion = "v5"
+)
+
+// VersionedClient client bound to an API version, as returned by Discover.
+// Type assert it to Client, ClientV4 or ClientV5 for the version specific operations.
+type VersionedClient interface {
+	// APIVersion REST API version served by the broker
+	APIVersion() APIVersion
+	// BrokerVersion broker release reported while probing, e.g. 4.3.10
+	BrokerVersion() string
+}
+
+// UnsupportedVersionError no supported REST API found on the broker
+type UnsupportedVersionError struct {
+	BaseURL string
+	// Version broker release, empty if no known API answered
+	Version string
+	// Probes failure of each probed API version
+	Probes map[APIVersion]error
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	if e.Version != "" {
+		return fmt.Sprintf("emqx: broker version %s at %s is not supported", e.Version, e.BaseURL)
+	}
+	reasons := []string{}
+	for _, v := range []APIVersion{APIVersionV5, APIVersionV4, APIVersionV3} {
+		if err, ok := e.Probes[v]; ok {
+			reasons = append(reasons, fmt.Sprintf("%s: %v", v, err))
+		}
+	}
+	return fmt.Sprintf("emqx: no supported API found at %s (%s)", e.BaseURL, strings.Join(reasons, "; "))
+}
+
+// APIVersion REST API version served by the broker
+func (a *APIClient) APIVersion() APIVersion {
+	return APIVersionV3
+}
+
+// BrokerVersion broker release reported while probing
+func (a *APIClient) BrokerVersion() string {
+	return a.brokerVersion
+}
+
+// APIVersion REST API version served by the broker
+func (a *APIClientV4) APIVersion() APIVersion {
+	return APIVersionV4
+}
+
+// BrokerVersion broker release reported while probing
+func (a *APIClientV4) BrokerVersion() string {
+	return a.brokerVersion
+}
+
+// APIVersion REST API version served by the broker
+func (a *APIClientV5) APIVersion() APIVersion {
+	return APIVersionV5
+}
+
+// BrokerVersion broker release reported while probing
+func (a *APIClientV5) BrokerVersion() string {
+	return a.brokerVersion
+}
+
+// Discover probe the broker at c.BaseURL for api/v5/, api/v4/ and api/v3/, newest first,
+// and return a client of the version it serves.
+// An API version is accepted when it lists its API descriptors (v3, v4) or nodes (v5)
+// and the broker release reported by it has the same major version.
+// It takes the whole config rather than a base url as probing needs its credentials, TLS and timeout.
+// The probes and the returned client share one transport, so probes count against its rate limits
+// and circuits.
+func Discover(ctx context.Context, c ClientConfig) (VersionedClient, error) {
+	probes := map[APIVersion]error{}
+	t := newTransport(c)
+
+	t.decodeError = decodeErrorV5
+	version, err := probeV5(ctx, t)
+	if err == nil {
+		v5 := newAPIClientV5(t)
+		v5.brokerVersion = version
+		return checkMajor(v5, t.BaseURL)
+	}
+	if isFatalProbeError(ctx, err) {
+		return nil, err
+	}
+	probes[APIVersionV5] = err
+
+	// v3 and v4 report errors in the response body
+	t.decodeError = nil
+	version, err = probeV3V4(ctx, t, APIVersionV4)
+	if err == nil {
+		v4 := newAPIClientV4(t)
+		v4.brokerVersion = version
+		return checkMajor(v4, t.BaseURL)
+	}
+	if isFatalProbeError(ctx, err) {
+		return nil, err
+	}
+	probes[APIVersionV4] = err
+
+	version, err = probeV3V4(ctx, t, APIVersionV3)
+	if err == nil {
+		v3 := newAPIClient(t)
+		v3.brokerVersion = version
+		return checkMajor(v3, t.BaseURL)
+	}
+	if isFatalProbeError(ctx, err) {
+		return nil, err
+	}
+	probes[APIVersionV3] = err
+
+	return nil, &UnsupportedVersionError{BaseURL: t.BaseURL, Probes: probes}
+}
+
+func probeV5(ctx context.Context, t *transport) (string, error) {
+	var nodes []NodeV5
+	if err := t.makeRequestContext(ctx, http.MethodGet, "api/v5/nodes", nil, &nodes); err != nil {
+		return "", err
+	}
+	if len(nodes) == 0 {
+		return "", fmt.Errorf("no node listed")
+	}
+	return nodes[0].Version, nil
+}
+
+// probeV3V4 v3 and v4 share the descriptors and brokers schema
+func probeV3V4(ctx context.Context, t *transport, v APIVersion) (string, error) {
+	var apis ListAPIResponseV3
+	if err := t.makeRequestContext(ctx, http.MethodGet, fmt.Sprintf("api/%s/", v), nil, &apis); err != nil {
+		return "", err
+	}
+	if apis.Code != 0 || len(apis.Data) == 0 {
+		return "", fmt.Errorf("no API listed, code %d", apis.Code)
+	}
+
+	var brokers ListClusterResponseV3
+	if err := t.makeRequestContext(ctx, http.MethodGet, fmt.Sprintf("api/%s/brokers/", v), nil, &brokers); err != nil {
+		return "", err
+	}
+	if brokers.Code != 0 || len(brokers.Data) == 0 {
+		return "", fmt.Errorf("no broker listed, code %d", brokers.Code)
+	}
+	return brokers.Data[0].Version, nil
+}
+
+// checkMajor ensure the broker release matches the API version
+func checkMajor(v VersionedClient, baseURL string) (VersionedClient, error) {
+	version := strings.TrimPrefix(v.BrokerVersion(), "v")
+	if strings.HasPrefix(version, strings.TrimPrefix(string(v.APIVersion()), "v")+".") {
+		return v, nil
+	}
+	return nil, &UnsupportedVersionError{BaseURL: baseURL, Version: v.BrokerVersion()}
+}
+
+// isFatalProbeError report errors telling nothing about the API version, probing stops:
+// the broker cannot be reached, ctx is done, the circuit is open or the rate limit was exceeded
+func isFatalProbeError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	for err != nil {
+		switch err.(type) {
+		case *url.Error, *ErrCircuitOpen, *ErrRateLimited:
+			return true
+		}
+		if err == context.Canceled || err == context.DeadlineExceeded {
+			return true
+		}
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = wrapper.Unwrap()
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package emqx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newVersionServer(version string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/":
+			w.Write([]byte(`{"code":0,"data":[{"name":"list_brokers","method":"GET","path":"/brokers/","descr":"A list of brokers in the cluster"}]}`))
+		case "/api/v4/brokers/":
+			w.Write([]byte(`{"code":0,"data":[{"node":"emqx@127.0.0.1","version":"` + version + `"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"not found"}`))
+		}
+	}))
+}
+
+func TestDiscover(t *testing.T) {
+	server := newVersionServer("4.3.10")
+	defer server.Close()
+
+	c, err := Discover(context.Background(), ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.APIVersion() != APIVersionV4 || c.BrokerVersion() != "4.3.10" {
+		t.Fatal(c.APIVersion(), c.BrokerVersion())
+	}
+	if _, ok := c.(ClientV4); !ok {
+		t.Fatal("expected ClientV4")
+	}
+}
+
+func TestDiscoverUnsupported(t *testing.T) {
+	server := newVersionServer("6.0.0")
+	defer server.Close()
+
+	_, err := Discover(context.Background(), ClientConfig{BaseURL: server.URL})
+	e, ok := err.(*UnsupportedVersionError)
+	if !ok {
+		t.Fatal(err)
+	}
+	if e.Version != "6.0.0" {
+		t.Fatal(e)
+	}
+}
+
+func TestDiscoverSharesRateLimit(t *testing.T) {
+	server := newVersionServer("4.3.10")
+	defer server.Close()
+
+	// the v5 probe and both v4 probe requests take the burst
+	c, err := Discover(context.Background(), ClientConfig{BaseURL: server.URL, RateLimit: RateLimit{Rate: 10, Burst: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := c.(ClientV4).ListCluster(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Millisecond*50 {
+		t.Fatalf("probes not counted by the rate limit, request sent after %s", elapsed)
+	}
+}
+
+func TestDiscoverCanceled(t *testing.T) {
+	server := newVersionServer("4.3.10")
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Discover(ctx, ClientConfig{BaseURL: server.URL})
+	if _, unsupported := err.(*UnsupportedVersionError); unsupported || !isContextError(err) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+}
+
+func TestDiscoverCircuitOpen(t *testing.T) {
+	server := newVersionServer("4.3.10")
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1})
+	breaker.Middleware()(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+	})(httptest.NewRequest(http.MethodGet, server.URL+"/api/v5/nodes", nil))
+
+	_, err := Discover(context.Background(), ClientConfig{BaseURL: server.URL, CircuitBreaker: breaker})
+	if _, ok := err.(*ErrCircuitOpen); !ok {
+		t.Fatalf("expected *ErrCircuitOpen, got %v", err)
+	}
+}
@@ -1,14 +1,10 @@
 package emqx
 
 import (
-	"bytes"
-	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -20,79 +16,54 @@ type ClientConfig struct {
 	AppID string
 	// EMQX Application Secret
 	AppSecret string
+	// Credentials provide application credentials on each request,
+	// take precedence over AppID and AppSecret if set
+	Credentials CredentialsProvider
+	// Authenticator produce the Authorization header, take precedence over
+	// Credentials, AppID and AppSecret if set, e.g. a LoginAuthenticator
+	Authenticator Authenticator
 	// EMQX client timeout
 	Timeout time.Duration
+	// TLS options for https base urls, e.g. a private CA and client certificate
+	TLS *TLSConfig
+	// Middlewares wrap every request, the first one is the outermost
+	Middlewares []Middleware
+	// DisableCoalescing send every GET, by default identical GETs in flight share one round trip
+	DisableCoalescing bool
+	// RequireCatalog fail fast with *ErrUnsupported requests to endpoints the broker does not list,
+	// the catalog is loaded on first request and reloaded after plugins are started or stopped
+	RequireCatalog bool
+	// Instrumentation observe every request, e.g. to emit spans and metrics
+	Instrumentation Instrumentation
+	// CircuitBreaker fail fast requests to a failing broker, may be shared by several clients
+	CircuitBreaker *CircuitBreaker
+	// RateLimit limit all requests, e.g. Rate and MaxInFlight
+	RateLimit RateLimit
+	// RateLimits limit requests per endpoint class, in addition to RateLimit
+	RateLimits map[EndpointClass]RateLimit
+	// Logger log every request, nothing is logged if nil
+	Logger Logger
+	// LogBodies log headers and bodies at debug level, secrets are redacted
+	LogBodies bool
 }
 
 // APIClient EMQX RESTFul API client
 type APIClient struct {
-	// BaseURL emqx RESTFul address
-	BaseURL    string
-	httpClient *http.Client
-	appID      string
-	appSecret  string
-	token      string
+	*transport
+	brokerVersion string
 }
 
 // NewAPIClient create client
 func NewAPIClient(c ClientConfig) Client {
-	a := &APIClient{
-		httpClient: http.DefaultClient,
-		appID:      c.AppID,
-		appSecret:  c.AppSecret,
-	}
-
-	if c.BaseURL == "" {
-		c.BaseURL = "http://localhost:8080"
-	}
-	a.BaseURL = c.BaseURL
-
-	if c.Timeout == 0 {
-		c.Timeout = time.Second * 5
-	}
-	a.httpClient.Timeout = c.Timeout
-
-	a.updateToken(c.AppID, c.AppSecret)
-
-	return a
+	return newAPIClient(newTransport(c))
 }
 
-// UpdateToken update token with appID and appSecret
-func (a *APIClient) updateToken(appID, appSecret string) {
-	str := fmt.Sprintf("%s:%s", appID, appSecret)
-	a.token = fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(str)))
-}
-
-// makeRequest makeRequest
-func (a *APIClient) makeRequest(method, endpoint string, payload []byte, resp interface{}) error {
-	url := fmt.Sprintf("%s/%s", a.BaseURL, endpoint)
-	var body io.Reader
-	if payload != nil {
-		body = bytes.NewBuffer(payload)
-	}
-	request, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return err
-	}
-
-	request.Header = http.Header{
-		"Authorization": []string{a.token},
-		"Content-Type":  []string{"application/json"},
-	}
-
-	response, err := a.httpClient.Do(request)
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-	content, _ := ioutil.ReadAll(response.Body)
-	err = json.Unmarshal(content, resp)
-	if err != nil {
-		return errors.New(string(content))
+func newAPIClient(t *transport) *APIClient {
+	a := &APIClient{
+		transport: t,
 	}
-
-	return nil
+	a.catalogPrefix, a.loadCatalog = "api/v3", a.LoadCatalog
+	return a
 }
 
 // ListAllAPI ListAllAPI
@@ -203,6 +174,89 @@ func (a *APIClient) GetNodeConnection(clientid string) (*NodeConnectionResponseV
 	return &resp, nil
 }
 
+// ListConnectionsByUsername ListConnectionsByUsername
+// List Connections of a Username in the Cluster
+// GET api/v3/connections/username/${username}
+func (a *APIClient) ListConnectionsByUsername(username string) (*ListConnectionsByUsernameResponseV3, error) {
+	var resp ListConnectionsByUsernameResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/connections/username/%s", username), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodeConnectionsByUsername ListNodeConnectionsByUsername
+// List Connections of a Username on a node
+// GET api/v3/nodes/${node}/connections/username/${username}
+func (a *APIClient) ListNodeConnectionsByUsername(node, username string) (*ListNodeConnectionsByUsernameResponseV3, error) {
+	var resp ListNodeConnectionsByUsernameResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/nodes/%s/connections/username/%s", node, username), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+//
+// ACL Cache
+//
+
+// ListClusterConnACLCache List ACL cache of a Connection in the Cluster
+// GET api/v3/connections/${clientid}/acl_cache
+func (a *APIClient) ListClusterConnACLCache(clientid string) (*ListACLCacheResponseV3, error) {
+	var resp ListACLCacheResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/connections/%s/acl_cache", clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodeConnACLCache List ACL cache of a Connection on a node
+// GET api/v3/nodes/${node}/connections/${clientid}/acl_cache
+func (a *APIClient) ListNodeConnACLCache(node, clientid string) (*ListACLCacheResponseV3, error) {
+	var resp ListACLCacheResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/nodes/%s/connections/%s/acl_cache", node, clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ClearClusterConnACLCache Clear ACL cache of a Connection in the Cluster
+// DELETE api/v3/connections/${clientid}/acl_cache
+func (a *APIClient) ClearClusterConnACLCache(clientid string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, fmt.Sprintf("api/v3/connections/%s/acl_cache", clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ClearNodeConnACLCache Clear ACL cache of a Connection on a node
+// DELETE api/v3/nodes/${node}/connections/${clientid}/acl_cache
+func (a *APIClient) ClearNodeConnACLCache(node, clientid string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, fmt.Sprintf("api/v3/nodes/%s/connections/%s/acl_cache", node, clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ClearClusterACLCache Clear ACL cache of all Connections in the Cluster
+// DELETE api/v3/acl_cache
+func (a *APIClient) ClearClusterACLCache() (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, "api/v3/acl_cache", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // ListClusterSessions ListClusterSessions
 // List all Sessions in the Cluster
 // GET api/v3/sessions/
@@ -251,6 +305,30 @@ func (a *APIClient) GetNodeSession(node, clientid string) (*GetNodeSessionRespon
 	return &resp, nil
 }
 
+// ListSessionsByUsername ListSessionsByUsername
+// List Sessions of a Username in the Cluster
+// GET api/v3/sessions/username/${username}
+func (a *APIClient) ListSessionsByUsername(username string) (*ListSessionsByUsernameResponseV3, error) {
+	var resp ListSessionsByUsernameResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/sessions/username/%s", username), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodeSessionsByUsername ListNodeSessionsByUsername
+// List Sessions of a Username on a Node
+// GET api/v3/nodes/${node}/sessions/username/${username}
+func (a *APIClient) ListNodeSessionsByUsername(node, username string) (*ListNodeSessionsByUsernameResponseV3, error) {
+	var resp ListNodeSessionsByUsernameResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/nodes/%s/sessions/username/%s", node, username), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // ListClusterSubscriptions ListClusterSubscriptions
 // List all Subscriptions in the Cluster
 // GET api/v3/subscriptions/
@@ -410,6 +488,7 @@ func (a *APIClient) StartNodePlugins(node, plugin string) (*NoContentResponse, e
 	if err != nil {
 		return nil, err
 	}
+	a.invalidateCatalog()
 	return &resp, nil
 }
 
@@ -421,6 +500,103 @@ func (a *APIClient) StopNodePlugins(node, plugin string) (*NoContentResponse, er
 	if err != nil {
 		return nil, err
 	}
+	a.invalidateCatalog()
+	return &resp, nil
+}
+
+// GetNodePluginConfig get config of a plugin in a node
+// GET api/v3/nodes/${node}/plugins/${plugin}
+func (a *APIClient) GetNodePluginConfig(node, plugin string) (*GetPluginConfigResponseV3, error) {
+	var resp GetPluginConfigResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/nodes/%s/plugins/%s", node, plugin), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateNodePluginConfig update config of a plugin in a node
+// PUT api/v3/nodes/${node}/plugins/${plugin}
+func (a *APIClient) UpdateNodePluginConfig(node, plugin string, config map[string]interface{}) (*NoContentResponse, error) {
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPut, fmt.Sprintf("api/v3/nodes/%s/plugins/%s", node, plugin), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetPluginConfig get config of a plugin in the cluster
+// GET api/v3/configs/${plugin}
+func (a *APIClient) GetPluginConfig(plugin string) (*GetPluginConfigResponseV3, error) {
+	var resp GetPluginConfigResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/configs/%s", plugin), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdatePluginConfig update config of a plugin in the cluster
+// PUT api/v3/configs/${plugin}
+func (a *APIClient) UpdatePluginConfig(plugin string, config map[string]interface{}) (*NoContentResponse, error) {
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPut, fmt.Sprintf("api/v3/configs/%s", plugin), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+//
+// Configs
+//
+
+// GetConfigs List all configs of Cluster
+// GET api/v3/configs/
+func (a *APIClient) GetConfigs() (*ListConfigsResponseV3, error) {
+	var resp ListConfigsResponseV3
+	err := a.makeRequest(http.MethodGet, "api/v3/configs/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetNodeConfigs List all configs in a node
+// GET api/v3/nodes/${node}/configs/
+func (a *APIClient) GetNodeConfigs(node string) (*GetNodeConfigsResponseV3, error) {
+	var resp GetNodeConfigsResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/nodes/%s/configs/", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateNodeConfig update configs of an app in a node
+// PUT api/v3/nodes/${node}/configs/${app}
+func (a *APIClient) UpdateNodeConfig(node, app string, config map[string]interface{}) (*NoContentResponse, error) {
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPut, fmt.Sprintf("api/v3/nodes/%s/configs/%s", node, app), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
 	return &resp, nil
 }
 
@@ -450,6 +626,33 @@ func (a *APIClient) ListNodeListeners(node string) (*ListNodeListenerResponseV3,
 	return &resp, nil
 }
 
+// RestartNodeListener restart a listener in a node, by listener ID or ListenOn
+// PUT api/v3/nodes/${node}/listeners/${listener}/restart
+func (a *APIClient) RestartNodeListener(node, listener string) (*NoContentResponse, error) {
+	return a.operateNodeListener(node, listener, "restart")
+}
+
+// StopNodeListener stop a listener in a node, by listener ID or ListenOn
+// PUT api/v3/nodes/${node}/listeners/${listener}/stop
+func (a *APIClient) StopNodeListener(node, listener string) (*NoContentResponse, error) {
+	return a.operateNodeListener(node, listener, "stop")
+}
+
+// StartNodeListener start a listener in a node, by listener ID or ListenOn
+// PUT api/v3/nodes/${node}/listeners/${listener}/start
+func (a *APIClient) StartNodeListener(node, listener string) (*NoContentResponse, error) {
+	return a.operateNodeListener(node, listener, "start")
+}
+
+func (a *APIClient) operateNodeListener(node, listener, operation string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodPut, fmt.Sprintf("api/v3/nodes/%s/listeners/%s/%s", node, url.PathEscape(listener), operation), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 //
 // Metrics
 //
@@ -475,3 +678,195 @@ func (a *APIClient) GetNodeMetrics(node string) (*GetNodeMetricsResponseV3, erro
 	}
 	return &resp, nil
 }
+
+// ListClusterStats List statistics of all nodes, e.g. connections/count
+// GET api/v3/stats/
+func (a *APIClient) ListClusterStats() (*ListClusterStatsResponseV3, error) {
+	var resp ListClusterStatsResponseV3
+	err := a.makeRequest(http.MethodGet, "api/v3/stats/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListClusterAlarms List present alarms of all nodes
+// GET api/v3/alarms/present
+func (a *APIClient) ListClusterAlarms() (*ListClusterAlarmsResponseV3, error) {
+	var resp ListClusterAlarmsResponseV3
+	err := a.makeRequest(http.MethodGet, "api/v3/alarms/present", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodeAlarms List present alarms of a node
+// GET api/v3/alarms/present/${node}
+func (a *APIClient) ListNodeAlarms(node string) (*ListNodeAlarmsResponseV3, error) {
+	var resp ListNodeAlarmsResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/alarms/present/%s", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+//
+// Applications
+//
+
+// ListApps List all applications
+// GET api/v3/apps/
+func (a *APIClient) ListApps() (*ListAppsResponseV3, error) {
+	var resp ListAppsResponseV3
+	err := a.makeRequest(http.MethodGet, "api/v3/apps/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetApp Retrieve an application
+// GET api/v3/apps/${appid}
+func (a *APIClient) GetApp(appid string) (*GetAppResponseV3, error) {
+	var resp GetAppResponseV3
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v3/apps/%s", appid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateApp create an application
+// POST api/v3/apps/
+func (a *APIClient) CreateApp(req *CreateAppRequestV3) (*CreateAppResponseV3, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CreateAppResponseV3
+	err = a.makeRequest(http.MethodPost, "api/v3/apps/", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateApp update an application
+// PUT api/v3/apps/${appid}
+func (a *APIClient) UpdateApp(appid string, req *UpdateAppRequestV3) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPut, fmt.Sprintf("api/v3/apps/%s", appid), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteApp delete an application
+// DELETE api/v3/apps/${appid}
+func (a *APIClient) DeleteApp(appid string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, fmt.Sprintf("api/v3/apps/%s", appid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+//
+// Users
+//
+
+// AuthUser authenticate a dashboard user
+// POST api/v3/auth
+func (a *APIClient) AuthUser(req *AuthUserRequestV3) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPost, "api/v3/auth", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListUsers List all dashboard users
+// GET api/v3/users/
+func (a *APIClient) ListUsers() (*ListUsersResponseV3, error) {
+	var resp ListUsersResponseV3
+	err := a.makeRequest(http.MethodGet, "api/v3/users/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateUser create a dashboard user
+// POST api/v3/users/
+func (a *APIClient) CreateUser(req *CreateUserRequestV3) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPost, "api/v3/users/", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateUser update a dashboard user
+// PUT api/v3/users/${username}
+func (a *APIClient) UpdateUser(username string, req *UpdateUserRequestV3) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPut, fmt.Sprintf("api/v3/users/%s", username), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteUser delete a dashboard user
+// DELETE api/v3/users/${username}
+func (a *APIClient) DeleteUser(username string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, fmt.Sprintf("api/v3/users/%s", username), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ChangeUserPassword change password of a dashboard user
+// PUT api/v3/change_pwd/${username}
+func (a *APIClient) ChangeUserPassword(username string, req *ChangeUserPasswordRequestV3) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPut, fmt.Sprintf("api/v3/change_pwd/%s", username), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -1,6 +1,9 @@
 package emqx
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -25,3 +28,124 @@ func TestCreateSubscription(t *testing.T) {
 		t.Fatal(resp.Code)
 	}
 }
+
+// newRouteServer serve the JSON response of each "METHOD path", unknown requests fail the test
+func newRouteServer(t *testing.T, routes map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+}
+
+func TestLookupByUsername(t *testing.T) {
+	server := newRouteServer(t, map[string]string{
+		"GET /api/v3/connections/username/u1":          `{"code":0,"data":[{"client_id":"c1","username":"u1"}]}`,
+		"GET /api/v3/nodes/n1/connections/username/u1": `{"code":0,"data":[{"client_id":"c1","username":"u1"}]}`,
+		"GET /api/v3/sessions/username/u1":             `{"code":0,"data":[{"client_id":"c1","username":"u1"}]}`,
+		"GET /api/v3/nodes/n1/sessions/username/u1":    `{"code":0,"data":[]}`,
+	})
+	defer server.Close()
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL})
+
+	conns, err := a.ListConnectionsByUsername("u1")
+	if err != nil || len(conns.Data) != 1 || conns.Data[0].ClientID != "c1" {
+		t.Fatal(conns, err)
+	}
+	nodeConns, err := a.ListNodeConnectionsByUsername("n1", "u1")
+	if err != nil || len(nodeConns.Data) != 1 {
+		t.Fatal(nodeConns, err)
+	}
+	sessions, err := a.ListSessionsByUsername("u1")
+	if err != nil || len(sessions.Data) != 1 || sessions.Data[0].ClientID != "c1" {
+		t.Fatal(sessions, err)
+	}
+	nodeSessions, err := a.ListNodeSessionsByUsername("n1", "u1")
+	if err != nil || len(nodeSessions.Data) != 0 {
+		t.Fatal(nodeSessions, err)
+	}
+}
+
+func TestACLCache(t *testing.T) {
+	server := newRouteServer(t, map[string]string{
+		"GET /api/v3/connections/c1/acl_cache":             `{"code":0,"data":[{"access":"publish","topic":"t/1","result":"allow","updated_time":1576832425}]}`,
+		"GET /api/v3/nodes/n1/connections/c1/acl_cache":    `{"code":0,"data":[]}`,
+		"DELETE /api/v3/connections/c1/acl_cache":          `{"code":0}`,
+		"DELETE /api/v3/nodes/n1/connections/c1/acl_cache": `{"code":0}`,
+		"DELETE /api/v3/acl_cache":                         `{"code":0}`,
+	})
+	defer server.Close()
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL})
+
+	cache, err := a.ListClusterConnACLCache("c1")
+	if err != nil || len(cache.Data) != 1 {
+		t.Fatal(cache, err)
+	}
+	want := ACLCacheEntryV3{Access: "publish", Topic: "t/1", Result: "allow", UpdatedTime: 1576832425}
+	if cache.Data[0] != want {
+		t.Fatalf("got %+v, want %+v", cache.Data[0], want)
+	}
+	if _, err := a.ListNodeConnACLCache("n1", "c1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ClearClusterConnACLCache("c1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ClearNodeConnACLCache("n1", "c1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ClearClusterACLCache(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApps(t *testing.T) {
+	routes := newRouteServer(t, map[string]string{
+		"GET /api/v3/apps/":      `{"code":0,"data":[{"app_id":"a1","name":"app","secret":"s1","desc":"","status":true,"expired":"undefined"}]}`,
+		"GET /api/v3/apps/a1":    `{"code":0,"data":{"app_id":"a1","name":"app","secret":"s1","desc":"","status":true,"expired":1893456000}}`,
+		"POST /api/v3/apps/":     `{"code":0,"data":{"secret":"generated"}}`,
+		"PUT /api/v3/apps/a1":    `{"code":0}`,
+		"DELETE /api/v3/apps/a1": `{"code":0}`,
+	})
+	defer routes.Close()
+	bodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies[r.Method] = string(body)
+		routes.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL})
+
+	apps, err := a.ListApps()
+	if err != nil || len(apps.Data) != 1 || apps.Data[0].AppID != "a1" || apps.Data[0].Expired != 0 {
+		t.Fatal(apps, err)
+	}
+	app, err := a.GetApp("a1")
+	if err != nil || app.Data.Expired != 1893456000 {
+		t.Fatal(app, err)
+	}
+
+	created, err := a.CreateApp(&CreateAppRequestV3{AppID: "a2", Name: "app", Status: true})
+	if err != nil || created.Data.Secret != "generated" {
+		t.Fatal(created, err)
+	}
+	// an empty secret is generated by the broker, no expiry is sent as undefined
+	if want := `{"app_id":"a2","name":"app","desc":"","status":true,"expired":"undefined"}`; bodies[http.MethodPost] != want {
+		t.Fatalf("got %s, want %s", bodies[http.MethodPost], want)
+	}
+
+	if _, err := a.UpdateApp("a1", &UpdateAppRequestV3{Name: "app", Expired: 1893456000}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"app","desc":"","status":false,"expired":1893456000}`; bodies[http.MethodPut] != want {
+		t.Fatalf("got %s, want %s", bodies[http.MethodPut], want)
+	}
+	if _, err := a.DeleteApp("a1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,393 @@
+package emqx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// APIClientV4 EMQX v4 RESTFul API client
+type APIClientV4 struct {
+	*transport
+	brokerVersion string
+}
+
+// NewAPIClientV4 create v4 client
+func NewAPIClientV4(c ClientConfig) ClientV4 {
+	return newAPIClientV4(newTransport(c))
+}
+
+func newAPIClientV4(t *transport) *APIClientV4 {
+	a := &APIClientV4{
+		transport: t,
+	}
+	a.catalogPrefix, a.loadCatalog = "api/v4", a.LoadCatalog
+	return a
+}
+
+// pageQuery append pagination query to endpoint
+func pageQuery(endpoint string, page *PageV4) string {
+	if page == nil {
+		return endpoint
+	}
+	q := url.Values{}
+	if page.Page > 0 {
+		q.Set("_page", strconv.Itoa(page.Page))
+	}
+	if page.Limit > 0 {
+		q.Set("_limit", strconv.Itoa(page.Limit))
+	}
+	if len(q) == 0 {
+		return endpoint
+	}
+	return endpoint + "?" + q.Encode()
+}
+
+// ListAllAPI List all API describe
+// GET api/v4/
+func (a *APIClientV4) ListAllAPI() (*ListAPIResponseV4, error) {
+	var resp ListAPIResponseV4
+	err := a.makeRequest(http.MethodGet, "api/v4/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListCluster List all Cluster
+// GET api/v4/brokers/
+func (a *APIClientV4) ListCluster() (*ListClusterResponseV4, error) {
+	var resp ListClusterResponseV4
+	err := a.makeRequest(http.MethodGet, "api/v4/brokers/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetNodeInfo Retrieve Info of a Node
+// GET api/v4/brokers/${node}
+func (a *APIClientV4) GetNodeInfo(node string) (*NodeInfoResponseV4, error) {
+	var resp NodeInfoResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/brokers/%s", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodeStats List Statistics of All Nodes in the Cluster
+// GET api/v4/nodes/
+func (a *APIClientV4) ListNodeStats() (*ListNodeStatResponseV4, error) {
+	var resp ListNodeStatResponseV4
+	err := a.makeRequest(http.MethodGet, "api/v4/nodes/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetNodeStat Retrieve Statistics of a Specific Node
+// GET api/v4/nodes/${node}
+func (a *APIClientV4) GetNodeStat(node string) (*NodeStatResponseV4, error) {
+	var resp NodeStatResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/nodes/%s", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListClients List all Clients in the Cluster
+// GET api/v4/clients
+func (a *APIClientV4) ListClients(page *PageV4) (*ListClientsResponseV4, error) {
+	var resp ListClientsResponseV4
+	err := a.makeRequest(http.MethodGet, pageQuery("api/v4/clients", page), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodeClients List all Clients in a node
+// GET api/v4/nodes/${node}/clients
+func (a *APIClientV4) ListNodeClients(node string, page *PageV4) (*ListClientsResponseV4, error) {
+	var resp ListClientsResponseV4
+	err := a.makeRequest(http.MethodGet, pageQuery(fmt.Sprintf("api/v4/nodes/%s/clients", node), page), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetClient Retrieve a Client in the Cluster
+// GET api/v4/clients/${clientid}
+func (a *APIClientV4) GetClient(clientid string) (*GetClientResponseV4, error) {
+	var resp GetClientResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/clients/%s", clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetNodeClient Retrieve a Client on a node
+// GET api/v4/nodes/${node}/clients/${clientid}
+func (a *APIClientV4) GetNodeClient(node, clientid string) (*GetClientResponseV4, error) {
+	var resp GetClientResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/nodes/%s/clients/%s", node, clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListClientsByUsername List Clients of a Username in the Cluster
+// GET api/v4/clients/username/${username}
+func (a *APIClientV4) ListClientsByUsername(username string) (*GetClientResponseV4, error) {
+	var resp GetClientResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/clients/username/%s", username), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// KickClient Kick a Client in the Cluster
+// DELETE api/v4/clients/${clientid}
+func (a *APIClientV4) KickClient(clientid string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, fmt.Sprintf("api/v4/clients/%s", clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListClientACLCache List ACL cache of a Client
+// GET api/v4/clients/${clientid}/acl_cache
+func (a *APIClientV4) ListClientACLCache(clientid string) (*ListACLCacheResponseV4, error) {
+	var resp ListACLCacheResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/clients/%s/acl_cache", clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ClearClientACLCache Clear ACL cache of a Client
+// DELETE api/v4/clients/${clientid}/acl_cache
+func (a *APIClientV4) ClearClientACLCache(clientid string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, fmt.Sprintf("api/v4/clients/%s/acl_cache", clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListSubscriptions List all Subscriptions in the Cluster
+// GET api/v4/subscriptions
+func (a *APIClientV4) ListSubscriptions(page *PageV4) (*ListSubscriptionsResponseV4, error) {
+	var resp ListSubscriptionsResponseV4
+	err := a.makeRequest(http.MethodGet, pageQuery("api/v4/subscriptions", page), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodeSubscriptions List all Subscriptions in a node
+// GET api/v4/nodes/${node}/subscriptions
+func (a *APIClientV4) ListNodeSubscriptions(node string, page *PageV4) (*ListSubscriptionsResponseV4, error) {
+	var resp ListSubscriptionsResponseV4
+	err := a.makeRequest(http.MethodGet, pageQuery(fmt.Sprintf("api/v4/nodes/%s/subscriptions", node), page), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListClientSubscriptions List Subscriptions of a Client in the Cluster
+// GET api/v4/subscriptions/${clientid}
+func (a *APIClientV4) ListClientSubscriptions(clientid string) (*ListClientSubscriptionsResponseV4, error) {
+	var resp ListClientSubscriptionsResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/subscriptions/%s", clientid), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListRoutes List all Routes in the Cluster
+// GET api/v4/routes
+func (a *APIClientV4) ListRoutes(page *PageV4) (*ListRoutesResponseV4, error) {
+	var resp ListRoutesResponseV4
+	err := a.makeRequest(http.MethodGet, pageQuery("api/v4/routes", page), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetTopicRoute Retrieve a Route of Topic in the Cluster
+// GET api/v4/routes/${topic}
+func (a *APIClientV4) GetTopicRoute(topic string) (*GetTopicRoutesResponseV4, error) {
+	var resp GetTopicRoutesResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/routes/%s", topic), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PublishMessage Publish message request
+// POST api/v4/mqtt/publish
+func (a *APIClientV4) PublishMessage(req *PublishMessageRequestV4) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPost, "api/v4/mqtt/publish", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateSubscription create subscription
+// POST api/v4/mqtt/subscribe
+func (a *APIClientV4) CreateSubscription(req *CreateSubscriptionRequestV4) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPost, "api/v4/mqtt/subscribe", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Unsubscribe unsubscribe
+// POST api/v4/mqtt/unsubscribe
+func (a *APIClientV4) Unsubscribe(req *UnSubscribeRequestV4) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPost, "api/v4/mqtt/unsubscribe", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListClusterPlugins List all Plugins of Cluster
+// GET api/v4/plugins/
+func (a *APIClientV4) ListClusterPlugins() (*ListClusterPluginResponseV4, error) {
+	var resp ListClusterPluginResponseV4
+	err := a.makeRequest(http.MethodGet, "api/v4/plugins/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodePlugins List all plugins in a node
+// GET api/v4/nodes/${node}/plugins/
+func (a *APIClientV4) ListNodePlugins(node string) (*ListNodePluginResponseV4, error) {
+	var resp ListNodePluginResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/nodes/%s/plugins/", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StartNodePlugins Start a plugin
+// PUT api/v4/nodes/${node}/plugins/${plugin}/load
+func (a *APIClientV4) StartNodePlugins(node, plugin string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodPut, fmt.Sprintf("api/v4/nodes/%s/plugins/%s/load", node, plugin), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	a.invalidateCatalog()
+	return &resp, nil
+}
+
+// StopNodePlugins stop a plugin
+// PUT api/v4/nodes/${node}/plugins/${plugin}/unload
+func (a *APIClientV4) StopNodePlugins(node, plugin string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodPut, fmt.Sprintf("api/v4/nodes/%s/plugins/%s/unload", node, plugin), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	a.invalidateCatalog()
+	return &resp, nil
+}
+
+// ListClusterListeners List all listeners of Cluster
+// GET api/v4/listeners/
+func (a *APIClientV4) ListClusterListeners() (*ListClusterListenersResponseV4, error) {
+	var resp ListClusterListenersResponseV4
+	err := a.makeRequest(http.MethodGet, "api/v4/listeners/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNodeListeners List all listeners in a node
+// GET api/v4/nodes/${node}/listeners/
+func (a *APIClientV4) ListNodeListeners(node string) (*ListNodeListenerResponseV4, error) {
+	var resp ListNodeListenerResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/nodes/%s/listeners/", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RestartNodeListener restart a listener in a node, by listener identifier
+// PUT api/v4/nodes/${node}/listeners/${identifier}/restart
+func (a *APIClientV4) RestartNodeListener(node, identifier string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodPut, fmt.Sprintf("api/v4/nodes/%s/listeners/%s/restart", node, url.PathEscape(identifier)), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListClusterMetrics List all metrics of Cluster
+// GET api/v4/metrics/
+func (a *APIClientV4) ListClusterMetrics() (*ListClusterMetricsResponseV4, error) {
+	var resp ListClusterMetricsResponseV4
+	err := a.makeRequest(http.MethodGet, "api/v4/metrics/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetNodeMetrics get all metrics in a node
+// GET api/v4/nodes/${node}/metrics/
+func (a *APIClientV4) GetNodeMetrics(node string) (*GetNodeMetricsResponseV4, error) {
+	var resp GetNodeMetricsResponseV4
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v4/nodes/%s/metrics/", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
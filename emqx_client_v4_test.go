@@ -0,0 +1,29 @@
+package emqx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListClientsV4(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/clients" || r.URL.Query().Get("_page") != "2" || r.URL.Query().Get("_limit") != "10" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`{"code":0,"data":[{"clientid":"c1","connected":true,"proto_ver":5}],"meta":{"page":2,"limit":10,"count":11}}`))
+	}))
+	defer server.Close()
+
+	a := NewAPIClientV4(ClientConfig{BaseURL: server.URL})
+	resp, err := a.ListClients(&PageV4{Page: 2, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ClientID != "c1" || !resp.Data[0].Connected || resp.Data[0].ProtoVer != 5 {
+		t.Fatal(resp.Data)
+	}
+	if resp.Meta.Count != 11 {
+		t.Fatal(resp.Meta)
+	}
+}
@@ -0,0 +1,339 @@
+package emqx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// APIClientV5 EMQX v5 RESTFul API client.
+// AppID and AppSecret of ClientConfig are the API key and secret,
+// set Authenticator to a BearerToken or a LoginAuthenticator to use bearer tokens instead.
+type APIClientV5 struct {
+	*transport
+	brokerVersion string
+}
+
+// NewAPIClientV5 create v5 client
+func NewAPIClientV5(c ClientConfig) ClientV5 {
+	return newAPIClientV5(newTransport(c))
+}
+
+func newAPIClientV5(t *transport) *APIClientV5 {
+	t.decodeError = decodeErrorV5
+	return &APIClientV5{
+		transport: t,
+	}
+}
+
+// decodeErrorV5 EMQX v5 reports errors with HTTP status and a string code
+func decodeErrorV5(statusCode int, content []byte) error {
+	if statusCode < http.StatusBadRequest {
+		return nil
+	}
+	e := &ErrorResponseV5{}
+	if err := json.Unmarshal(content, e); err != nil {
+		e.Message = string(content)
+	}
+	e.StatusCode = statusCode
+	return e
+}
+
+// withQuery append non empty query values to endpoint
+func withQuery(endpoint string, q url.Values) string {
+	for k, v := range q {
+		if len(v) == 0 || v[0] == "" {
+			delete(q, k)
+		}
+	}
+	if len(q) == 0 {
+		return endpoint
+	}
+	return endpoint + "?" + q.Encode()
+}
+
+// positive format n as query value, empty if not set
+func positive(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+//
+// Nodes
+//
+
+// ListNodes List all Nodes in the Cluster
+// GET api/v5/nodes
+func (a *APIClientV5) ListNodes() ([]NodeV5, error) {
+	var resp []NodeV5
+	err := a.makeRequest(http.MethodGet, "api/v5/nodes", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetNode Retrieve Info of a Node
+// GET api/v5/nodes/${node}
+func (a *APIClientV5) GetNode(node string) (*NodeV5, error) {
+	var resp NodeV5
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v5/nodes/%s", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetStats Get aggregated Statistics of the Cluster
+// GET api/v5/stats?aggregate=true
+func (a *APIClientV5) GetStats() (StatsV5, error) {
+	var resp StatsV5
+	err := a.makeRequest(http.MethodGet, "api/v5/stats?aggregate=true", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetNodeStats Get Statistics of a Node
+// GET api/v5/nodes/${node}/stats
+func (a *APIClientV5) GetNodeStats(node string) (StatsV5, error) {
+	var resp StatsV5
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v5/nodes/%s/stats", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetMetrics Get aggregated Metrics of the Cluster
+// GET api/v5/metrics?aggregate=true
+func (a *APIClientV5) GetMetrics() (MetricsV5, error) {
+	var resp MetricsV5
+	err := a.makeRequest(http.MethodGet, "api/v5/metrics?aggregate=true", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetNodeMetrics Get Metrics of a Node
+// GET api/v5/nodes/${node}/metrics
+func (a *APIClientV5) GetNodeMetrics(node string) (MetricsV5, error) {
+	var resp MetricsV5
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v5/nodes/%s/metrics", node), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+//
+// Clients
+//
+
+// ListClients List Clients in the Cluster
+// GET api/v5/clients
+func (a *APIClientV5) ListClients(query *ListClientsQueryV5) (*ListClientsResponseV5, error) {
+	if query == nil {
+		query = &ListClientsQueryV5{}
+	}
+	endpoint := withQuery("api/v5/clients", url.Values{
+		"page":       []string{positive(query.Page)},
+		"limit":      []string{positive(query.Limit)},
+		"node":       []string{query.Node},
+		"username":   []string{query.Username},
+		"conn_state": []string{query.ConnState},
+	})
+
+	var resp ListClientsResponseV5
+	err := a.makeRequest(http.MethodGet, endpoint, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetClient Retrieve a Client in the Cluster
+// GET api/v5/clients/${clientid}
+func (a *APIClientV5) GetClient(clientid string) (*ClientInfoV5, error) {
+	var resp ClientInfoV5
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v5/clients/%s", url.PathEscape(clientid)), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// KickClient Kick a Client in the Cluster
+// DELETE api/v5/clients/${clientid}
+func (a *APIClientV5) KickClient(clientid string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, fmt.Sprintf("api/v5/clients/%s", url.PathEscape(clientid)), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+//
+// Subscriptions
+//
+
+// ListClientSubscriptions List Subscriptions of a Client
+// GET api/v5/clients/${clientid}/subscriptions
+func (a *APIClientV5) ListClientSubscriptions(clientid string) ([]SubscriptionV5, error) {
+	var resp []SubscriptionV5
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v5/clients/%s/subscriptions", url.PathEscape(clientid)), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Subscribe Subscribe a Client to a topic
+// POST api/v5/clients/${clientid}/subscribe
+func (a *APIClientV5) Subscribe(clientid string, req *SubscribeRequestV5) (*SubscriptionV5, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp SubscriptionV5
+	err = a.makeRequest(http.MethodPost, fmt.Sprintf("api/v5/clients/%s/subscribe", url.PathEscape(clientid)), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Unsubscribe Unsubscribe a Client from a topic
+// POST api/v5/clients/${clientid}/unsubscribe
+func (a *APIClientV5) Unsubscribe(clientid string, req *UnsubscribeRequestV5) (*NoContentResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp NoContentResponse
+	err = a.makeRequest(http.MethodPost, fmt.Sprintf("api/v5/clients/%s/unsubscribe", url.PathEscape(clientid)), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListSubscriptions List Subscriptions in the Cluster
+// GET api/v5/subscriptions
+func (a *APIClientV5) ListSubscriptions(query *ListSubscriptionsQueryV5) (*ListSubscriptionsResponseV5, error) {
+	if query == nil {
+		query = &ListSubscriptionsQueryV5{}
+	}
+	endpoint := withQuery("api/v5/subscriptions", url.Values{
+		"page":        []string{positive(query.Page)},
+		"limit":       []string{positive(query.Limit)},
+		"node":        []string{query.Node},
+		"clientid":    []string{query.ClientID},
+		"match_topic": []string{query.MatchTopic},
+	})
+
+	var resp ListSubscriptionsResponseV5
+	err := a.makeRequest(http.MethodGet, endpoint, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+//
+// Publish
+//
+
+// PublishMessage Publish message request
+// POST api/v5/publish
+func (a *APIClientV5) PublishMessage(req *PublishMessageRequestV5) (*PublishMessageResponseV5, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp PublishMessageResponseV5
+	err = a.makeRequest(http.MethodPost, "api/v5/publish", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+//
+// Authentication
+//
+
+// ListAuthenticationSources List all authentication sources of the global chain
+// GET api/v5/authentication
+func (a *APIClientV5) ListAuthenticationSources() ([]AuthenticationSourceV5, error) {
+	var resp []AuthenticationSourceV5
+	err := a.makeRequest(http.MethodGet, "api/v5/authentication", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetAuthenticationSource Retrieve an authentication source
+// GET api/v5/authentication/${id}
+func (a *APIClientV5) GetAuthenticationSource(id string) (AuthenticationSourceV5, error) {
+	var resp AuthenticationSourceV5
+	err := a.makeRequest(http.MethodGet, fmt.Sprintf("api/v5/authentication/%s", url.PathEscape(id)), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateAuthenticationSource Create an authentication source
+// POST api/v5/authentication
+func (a *APIClientV5) CreateAuthenticationSource(source AuthenticationSourceV5) (AuthenticationSourceV5, error) {
+	payload, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp AuthenticationSourceV5
+	err = a.makeRequest(http.MethodPost, "api/v5/authentication", payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateAuthenticationSource Update an authentication source
+// PUT api/v5/authentication/${id}
+func (a *APIClientV5) UpdateAuthenticationSource(id string, source AuthenticationSourceV5) (AuthenticationSourceV5, error) {
+	payload, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp AuthenticationSourceV5
+	err = a.makeRequest(http.MethodPut, fmt.Sprintf("api/v5/authentication/%s", url.PathEscape(id)), payload, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteAuthenticationSource Delete an authentication source
+// DELETE api/v5/authentication/${id}
+func (a *APIClientV5) DeleteAuthenticationSource(id string) (*NoContentResponse, error) {
+	var resp NoContentResponse
+	err := a.makeRequest(http.MethodDelete, fmt.Sprintf("api/v5/authentication/%s", url.PathEscape(id)), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
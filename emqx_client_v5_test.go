@@ -0,0 +1,44 @@
+package emqx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetNodeStatsV5(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected authorization %s", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"node":"emqx@127.0.0.1","connections.count":3,"topics.max":10}`))
+	}))
+	defer server.Close()
+
+	a := NewAPIClientV5(ClientConfig{BaseURL: server.URL, Authenticator: BearerToken("token")})
+	stats, err := a.GetNodeStats("emqx@127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["connections.count"] != 3 || len(stats) != 2 {
+		t.Fatal(stats)
+	}
+}
+
+func TestErrorResponseV5(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"CLIENTID_NOT_FOUND","message":"Client ID not found"}`))
+	}))
+	defer server.Close()
+
+	a := NewAPIClientV5(ClientConfig{BaseURL: server.URL})
+	_, err := a.GetClient("c1")
+	e, ok := err.(*ErrorResponseV5)
+	if !ok {
+		t.Fatal(err)
+	}
+	if e.StatusCode != http.StatusNotFound || e.Code != "CLIENTID_NOT_FOUND" {
+		t.Fatal(e)
+	}
+}
@@ -0,0 +1,177 @@
+package emqx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FanOutOptions per node fan out options
+type FanOutOptions struct {
+	// Concurrency nodes called at once, default to 4
+	Concurrency int
+}
+
+// NodeErrors per node errors of a fan out, keyed by node name
+type NodeErrors map[string]error
+
+func (e NodeErrors) Error() string {
+	nodes := make([]string, 0, len(e))
+	for node := range e {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	msgs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", node, e[node]))
+	}
+	return fmt.Sprintf("emqx: %d node(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Err return e as error, nil if no node failed
+func (e NodeErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ClusterNodes list the names of the nodes of the cluster
+func ClusterNodes(c Client) ([]string, error) {
+	cluster, err := c.ListCluster()
+	if err != nil {
+		return nil, err
+	}
+	if err := codeError(cluster.Code); err != nil {
+		return nil, err
+	}
+	nodes := make([]string, 0, len(cluster.Data))
+	for _, n := range cluster.Data {
+		nodes = append(nodes, n.Node)
+	}
+	return nodes, nil
+}
+
+// ForEachNode call fn on every node of the cluster, listed with ListCluster, with bounded concurrency.
+// It returns the values and errors of fn by node; nodes not called before ctx is done fail with its error.
+// The returned error is only set if the nodes cannot be listed.
+func ForEachNode(ctx context.Context, c Client, opts FanOutOptions, fn func(node string) (interface{}, error)) (map[string]interface{}, NodeErrors, error) {
+	nodes, err := ClusterNodes(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		values = map[string]interface{}{}
+		errs   = NodeErrors{}
+		slots  = make(chan struct{}, opts.Concurrency)
+	)
+	skip := func(node string) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs[node] = ctx.Err()
+	}
+	for _, node := range nodes {
+		if ctx.Err() != nil {
+			skip(node)
+			continue
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			skip(node)
+			continue
+		}
+
+		wg.Add(1)
+		go func(node string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			v, err := fn(node)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[node] = err
+				return
+			}
+			values[node] = v
+		}(node)
+	}
+	wg.Wait()
+	return values, errs, nil
+}
+
+// ListConnectionsPerNode call ListNodeConnections on every node
+func ListConnectionsPerNode(ctx context.Context, c Client, opts FanOutOptions) (map[string][]ConnectionV3, NodeErrors, error) {
+	values, errs, err := ForEachNode(ctx, c, opts, func(node string) (interface{}, error) {
+		resp, err := c.ListNodeConnections(node)
+		if err != nil {
+			return nil, err
+		}
+		if err := codeError(resp.Code); err != nil {
+			return nil, err
+		}
+		return resp.Data, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	conns := make(map[string][]ConnectionV3, len(values))
+	for node, v := range values {
+		conns[node] = v.([]ConnectionV3)
+	}
+	return conns, errs, nil
+}
+
+// GetMetricsPerNode call GetNodeMetrics on every node
+func GetMetricsPerNode(ctx context.Context, c Client, opts FanOutOptions) (map[string]MetricsV3, NodeErrors, error) {
+	values, errs, err := ForEachNode(ctx, c, opts, func(node string) (interface{}, error) {
+		resp, err := c.GetNodeMetrics(node)
+		if err != nil {
+			return nil, err
+		}
+		if err := codeError(resp.Code); err != nil {
+			return nil, err
+		}
+		return resp.Data, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	metrics := make(map[string]MetricsV3, len(values))
+	for node, v := range values {
+		metrics[node] = v.(MetricsV3)
+	}
+	return metrics, errs, nil
+}
+
+// ListPluginsPerNode call ListNodePlugins on every node
+func ListPluginsPerNode(ctx context.Context, c Client, opts FanOutOptions) (map[string][]PluginV3, NodeErrors, error) {
+	values, errs, err := ForEachNode(ctx, c, opts, func(node string) (interface{}, error) {
+		resp, err := c.ListNodePlugins(node)
+		if err != nil {
+			return nil, err
+		}
+		if err := codeError(resp.Code); err != nil {
+			return nil, err
+		}
+		return resp.Data, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	plugins := make(map[string][]PluginV3, len(values))
+	for node, v := range values {
+		plugins[node] = v.([]PluginV3)
+	}
+	return plugins, errs, nil
+}
@@ -0,0 +1,69 @@
+package emqx
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fanOutClient struct {
+	Client
+	inFlight, maxInFlight int32
+}
+
+func (c *fanOutClient) ListCluster() (*ListClusterResponseV3, error) {
+	return &ListClusterResponseV3{Data: []ClusterV3{{Node: "n1"}, {Node: "n2"}, {Node: "n3"}, {Node: "n4"}}}, nil
+}
+
+func (c *fanOutClient) ListNodePlugins(node string) (*ListNodePluginResponseV3, error) {
+	n := atomic.AddInt32(&c.inFlight, 1)
+	defer atomic.AddInt32(&c.inFlight, -1)
+	for {
+		m := atomic.LoadInt32(&c.maxInFlight)
+		if n <= m || atomic.CompareAndSwapInt32(&c.maxInFlight, m, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond * 10)
+
+	switch node {
+	case "n2":
+		return nil, errors.New("node down")
+	case "n3":
+		return &ListNodePluginResponseV3{Code: 104}, nil
+	}
+	return &ListNodePluginResponseV3{Data: []PluginV3{{Name: PluginWebHook}}}, nil
+}
+
+func TestListPluginsPerNode(t *testing.T) {
+	c := &fanOutClient{}
+	plugins, errs, err := ListPluginsPerNode(context.Background(), c, FanOutOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plugins) != 2 || plugins["n1"][0].Name != PluginWebHook || len(plugins["n4"]) != 1 {
+		t.Fatalf("unexpected plugins %v", plugins)
+	}
+	if len(errs) != 2 || errs["n2"] == nil || errs["n3"] == nil || errs.Err() == nil {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if c.maxInFlight > 2 {
+		t.Fatalf("%d nodes called at once", c.maxInFlight)
+	}
+}
+
+func TestForEachNodeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	values, errs, err := ForEachNode(ctx, &fanOutClient{}, FanOutOptions{Concurrency: 1}, func(node string) (interface{}, error) {
+		return node, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 0 || len(errs) != 4 || errs["n4"] != context.Canceled {
+		t.Fatalf("unexpected results %v %v", values, errs)
+	}
+}
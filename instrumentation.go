@@ -0,0 +1,252 @@
+package emqx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error types of RequestResult
+const (
+	ErrorTypeUnsupported = "unsupported"
+	ErrorTypeAuth        = "auth"
+	ErrorTypeRequest     = "request"
+	ErrorTypeNetwork     = "network"
+	ErrorTypeCircuitOpen = "circuit_open"
+	ErrorTypeHTTP        = "http"
+	ErrorTypeEMQX        = "emqx"
+	ErrorTypeDecode      = "decode"
+)
+
+// RequestInfo request being instrumented
+type RequestInfo struct {
+	// Method HTTP method
+	Method string
+	// Path request path relative to the base url, without query
+	Path string
+	// Endpoint route template, e.g. api/v3/nodes/:node/plugins/:plugin/load
+	Endpoint string
+	// Name descriptor name from the loaded catalog, e.g. load_plugin, empty if unknown
+	Name string
+	// Params path parameters, e.g. node and clientid
+	Params map[string]string
+}
+
+// RequestResult outcome of an instrumented request
+type RequestResult struct {
+	// StatusCode HTTP status, 0 if no response was received
+	StatusCode int
+	// Code EMQX response code, empty if absent or 0
+	Code string
+	// ErrorType classify failed requests, one of the ErrorType constants, empty on success
+	ErrorType string
+	Duration  time.Duration
+	Err       error
+	// CatalogErr the catalog required by RequireCatalog could not be loaded,
+	// the request was sent without being checked
+	CatalogErr error
+}
+
+// Instrumentation observe every request of a client, e.g. to emit spans and metrics.
+// StartRequest is called before the request is sent, the returned context is used for the
+// request, so trace context can be propagated by a Middleware; the returned func is called
+// once the request completes. Implementations must be safe for concurrent use.
+// The package does not depend on OpenTelemetry; an adapter starts a span in StartRequest
+// and ends it, with the RequestResult as attributes, in the returned func.
+type Instrumentation interface {
+	StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(RequestResult))
+}
+
+// pathParams name of the parameter following a collection segment
+var pathParams = map[string]string{
+	"brokers":        "node",
+	"nodes":          "node",
+	"connections":    "clientid",
+	"sessions":       "clientid",
+	"subscriptions":  "clientid",
+	"clients":        "clientid",
+	"username":       "username",
+	"routes":         "topic",
+	"plugins":        "plugin",
+	"listeners":      "listener",
+	"configs":        "app",
+	"apps":           "appid",
+	"users":          "username",
+	"change_pwd":     "username",
+	"authentication": "id",
+	"present":        "node",
+}
+
+// newRequestInfo derive the route template and path parameters of a request
+func newRequestInfo(method, endpoint string, catalog *Catalog) RequestInfo {
+	path := endpoint
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	info := RequestInfo{
+		Method: method,
+		Path:   path,
+		Params: map[string]string{},
+	}
+
+	segments := strings.Split(path, "/")
+	for i := 0; i < len(segments); i++ {
+		name, ok := pathParams[segments[i]]
+		if !ok || i+1 >= len(segments) || segments[i+1] == "" {
+			continue
+		}
+		if _, keyword := pathParams[segments[i+1]]; keyword {
+			continue
+		}
+		if name == "topic" {
+			// topics keep their slashes
+			info.Params[name] = strings.Join(segments[i+1:], "/")
+			segments = append(segments[:i+1], ":"+name)
+			break
+		}
+		info.Params[name] = segments[i+1]
+		segments[i+1] = ":" + name
+		i++
+	}
+	info.Endpoint = strings.Join(segments, "/")
+
+	if catalog != nil {
+		if api, ok := catalog.Lookup(method, path); ok {
+			info.Name = api.Name
+		}
+	}
+	return info
+}
+
+// responseCode extract the EMQX code of a response body
+func responseCode(content []byte) string {
+	var body struct {
+		Code json.RawMessage `json:"code"`
+	}
+	if json.Unmarshal(content, &body) != nil || len(body.Code) == 0 {
+		return ""
+	}
+	code := strings.Trim(string(body.Code), `"`)
+	if code == "0" {
+		return ""
+	}
+	return code
+}
+
+func classifyResponse(statusCode int, code string) string {
+	switch {
+	case statusCode >= http.StatusBadRequest:
+		return ErrorTypeHTTP
+	case code != "":
+		return ErrorTypeEMQX
+	default:
+		return ""
+	}
+}
+
+// DefaultLatencyBuckets upper bounds of the latency histogram of RequestMetrics
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond * 5,
+	time.Millisecond * 10,
+	time.Millisecond * 25,
+	time.Millisecond * 50,
+	time.Millisecond * 100,
+	time.Millisecond * 250,
+	time.Millisecond * 500,
+	time.Second,
+	time.Second * 2,
+	time.Second * 5,
+}
+
+// EndpointMetrics client side metrics of an endpoint
+type EndpointMetrics struct {
+	// Requests number of completed requests
+	Requests int64
+	// Errors number of failed requests by error type
+	Errors map[string]int64
+	// LatencyBuckets cumulative request count per upper bound of Buckets, the last one counts all
+	LatencyBuckets []int64
+	// Buckets latency upper bounds
+	Buckets []time.Duration
+	// LatencySum total latency
+	LatencySum time.Duration
+}
+
+// RequestMetrics in memory Instrumentation recording request count, latency histogram and
+// error count by type per method and endpoint template, e.g. to export to any metrics system
+type RequestMetrics struct {
+	buckets []time.Duration
+
+	mu        sync.Mutex
+	endpoints map[string]*EndpointMetrics
+}
+
+// NewRequestMetrics create a recorder, DefaultLatencyBuckets are used if buckets is empty
+func NewRequestMetrics(buckets ...time.Duration) *RequestMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]time.Duration(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &RequestMetrics{
+		buckets:   sorted,
+		endpoints: map[string]*EndpointMetrics{},
+	}
+}
+
+// StartRequest implement Instrumentation
+func (m *RequestMetrics) StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(RequestResult)) {
+	key := info.Method + " " + info.Endpoint
+	return ctx, func(r RequestResult) {
+		m.record(key, r)
+	}
+}
+
+func (m *RequestMetrics) record(key string, r RequestResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.endpoints[key]
+	if !ok {
+		e = &EndpointMetrics{
+			Errors:         map[string]int64{},
+			LatencyBuckets: make([]int64, len(m.buckets)+1),
+			Buckets:        m.buckets,
+		}
+		m.endpoints[key] = e
+	}
+
+	e.Requests++
+	e.LatencySum += r.Duration
+	if r.ErrorType != "" {
+		e.Errors[r.ErrorType]++
+	}
+	for i, b := range m.buckets {
+		if r.Duration <= b {
+			e.LatencyBuckets[i]++
+		}
+	}
+	e.LatencyBuckets[len(m.buckets)]++
+}
+
+// Snapshot copy the metrics, keyed by method and endpoint template, e.g. GET api/v3/brokers/
+func (m *RequestMetrics) Snapshot() map[string]EndpointMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]EndpointMetrics, len(m.endpoints))
+	for k, e := range m.endpoints {
+		c := *e
+		c.Errors = make(map[string]int64, len(e.Errors))
+		for t, n := range e.Errors {
+			c.Errors[t] = n
+		}
+		c.LatencyBuckets = append([]int64(nil), e.LatencyBuckets...)
+		snapshot[k] = c
+	}
+	return snapshot
+}
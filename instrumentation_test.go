@@ -0,0 +1,113 @@
+package emqx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNewRequestInfo(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		params   map[string]string
+	}{
+		{"api/v3/brokers/", "api/v3/brokers/", map[string]string{}},
+		{"api/v3/nodes/emqx@127.0.0.1/plugins/emqx_web_hook/load",
+			"api/v3/nodes/:node/plugins/:plugin/load",
+			map[string]string{"node": "emqx@127.0.0.1", "plugin": "emqx_web_hook"}},
+		{"api/v3/connections/username/u1?_limit=10",
+			"api/v3/connections/username/:username",
+			map[string]string{"username": "u1"}},
+		{"api/v3/nodes/n1/connections/c1/acl_cache",
+			"api/v3/nodes/:node/connections/:clientid/acl_cache",
+			map[string]string{"node": "n1", "clientid": "c1"}},
+		{"api/v3/routes/a/b/c", "api/v3/routes/:topic", map[string]string{"topic": "a/b/c"}},
+	}
+	for _, tt := range tests {
+		info := newRequestInfo(http.MethodGet, tt.endpoint, nil)
+		if info.Endpoint != tt.want {
+			t.Errorf("%s: endpoint %s, want %s", tt.endpoint, info.Endpoint, tt.want)
+		}
+		if !reflect.DeepEqual(info.Params, tt.params) {
+			t.Errorf("%s: params %v, want %v", tt.endpoint, info.Params, tt.params)
+		}
+	}
+}
+
+func TestRequestMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/nodes/n1/plugins/":
+			w.Write([]byte(`{"code":0,"data":[]}`))
+		case "/api/v3/nodes/n2/plugins/":
+			w.Write([]byte(`{"code":104,"message":"node not found"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+		}
+	}))
+	defer server.Close()
+
+	metrics := NewRequestMetrics()
+	var infos []RequestInfo
+	var results []RequestResult
+	c := NewAPIClient(ClientConfig{
+		BaseURL:         server.URL,
+		Instrumentation: instrumentations{metrics, recorder{&infos, &results}},
+	})
+
+	c.ListNodePlugins("n1")
+	c.ListNodePlugins("n2")
+	c.ListNodeStats()
+
+	snapshot := metrics.Snapshot()
+	plugins := snapshot["GET api/v3/nodes/:node/plugins/"]
+	if plugins.Requests != 2 || plugins.Errors[ErrorTypeEMQX] != 1 {
+		t.Fatalf("unexpected plugins metrics %+v", plugins)
+	}
+	if n := plugins.LatencyBuckets[len(plugins.LatencyBuckets)-1]; n != 2 {
+		t.Fatalf("unexpected latency count %d", n)
+	}
+	nodes := snapshot["GET api/v3/nodes/"]
+	if nodes.Requests != 1 || nodes.Errors[ErrorTypeHTTP] != 1 {
+		t.Fatalf("unexpected nodes metrics %+v", nodes)
+	}
+
+	if len(results) != 3 || infos[1].Params["node"] != "n2" {
+		t.Fatalf("unexpected requests %+v", infos)
+	}
+	if results[1].Code != "104" || results[2].StatusCode != http.StatusNotFound || results[2].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+
+type instrumentations []Instrumentation
+
+func (is instrumentations) StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(RequestResult)) {
+	ends := make([]func(RequestResult), 0, len(is))
+	for _, i := range is {
+		var end func(RequestResult)
+		ctx, end = i.StartRequest(ctx, info)
+		ends = append(ends, end)
+	}
+	return ctx, func(r RequestResult) {
+		for _, end := range ends {
+			end(r)
+		}
+	}
+}
+
+type recorder struct {
+	infos   *[]RequestInfo
+	results *[]RequestResult
+}
+
+func (r recorder) StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(RequestResult)) {
+	*r.infos = append(*r.infos, info)
+	return ctx, func(result RequestResult) {
+		*r.results = append(*r.results, result)
+	}
+}
@@ -0,0 +1,97 @@
+package emqx
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RollingRestartOptions options of RollingRestartListener
+type RollingRestartOptions struct {
+	// RecoverRatio fraction of the connections before restart to wait for, default to 0.9
+	RecoverRatio float64
+	// PollInterval interval between listener checks, default to 2s
+	PollInterval time.Duration
+	// RecoverTimeout max time to wait for a node to recover, default to 2m
+	RecoverTimeout time.Duration
+}
+
+// RollingRestartListener restart a listener on every node returned by ListCluster, one node at a time.
+// After restarting a node, it waits until CurrentConns of the listener recovers to
+// RecoverRatio of the value before restart, then moves on to the next node.
+// The listener is matched by listener ID or ListenOn.
+func RollingRestartListener(ctx context.Context, c Client, listener string, opts RollingRestartOptions) error {
+	if opts.RecoverRatio == 0 {
+		opts.RecoverRatio = 0.9
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = time.Second * 2
+	}
+	if opts.RecoverTimeout == 0 {
+		opts.RecoverTimeout = time.Minute * 2
+	}
+
+	cluster, err := c.ListCluster()
+	if err != nil {
+		return err
+	}
+
+	for _, n := range cluster.Data {
+		if err := restartNodeListener(ctx, c, n.Node, listener, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restartNodeListener(ctx context.Context, c Client, node, listener string, opts RollingRestartOptions) error {
+	before, err := nodeListener(c, node, listener)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.RestartNodeListener(node, listener)
+	if err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("restart listener %s on node %s failed: code %d, %s", listener, node, resp.Code, resp.Message)
+	}
+
+	target := int(math.Ceil(float64(before.CurrentConns) * opts.RecoverRatio))
+	deadline := time.Now().Add(opts.RecoverTimeout)
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		current, err := nodeListener(c, node, listener)
+		if err == nil && current.CurrentConns >= target {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("listener %s on node %s did not recover: %d/%d connections", listener, node, current.CurrentConns, target)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func nodeListener(c Client, node, listener string) (*ListenerV3, error) {
+	resp, err := c.ListNodeListeners(node)
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range resp.Data {
+		if l.Identifier == listener || l.ListenOn == listener {
+			return &resp.Data[i], nil
+		}
+	}
+	return nil, fmt.Errorf("listener %s not found on node %s", listener, node)
+}
@@ -0,0 +1,109 @@
+package emqx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type listenerTestClient struct {
+	Client
+	conns     map[string]int
+	restarted []string
+}
+
+func (c *listenerTestClient) ListCluster() (*ListClusterResponseV3, error) {
+	return &ListClusterResponseV3{Data: []ClusterV3{{Node: "emqx@node1"}, {Node: "emqx@node2"}}}, nil
+}
+
+func (c *listenerTestClient) ListNodeListeners(node string) (*ListNodeListenerResponseV3, error) {
+	conns := c.conns[node]
+	// connections come back gradually after a restart
+	if c.conns[node] < 10 {
+		c.conns[node] += 5
+	}
+	return &ListNodeListenerResponseV3{Data: []ListenerV3{{ListenOn: "0.0.0.0:1883", CurrentConns: conns}}}, nil
+}
+
+func (c *listenerTestClient) RestartNodeListener(node, listener string) (*NoContentResponse, error) {
+	c.restarted = append(c.restarted, node)
+	c.conns[node] = 0
+	return &NoContentResponse{}, nil
+}
+
+func TestRollingRestartListener(t *testing.T) {
+	c := &listenerTestClient{conns: map[string]int{"emqx@node1": 10, "emqx@node2": 10}}
+	err := RollingRestartListener(context.Background(), c, "0.0.0.0:1883", RollingRestartOptions{
+		RecoverRatio: 1,
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.restarted) != 2 {
+		t.Fatal(c.restarted)
+	}
+}
+
+func TestRollingRestartListenerNotFound(t *testing.T) {
+	c := &listenerTestClient{conns: map[string]int{}}
+	err := RollingRestartListener(context.Background(), c, "mqtt:ssl", RollingRestartOptions{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestRollingRestartListenerJSON(t *testing.T) {
+	conns := map[string]int{"emqx@node1": 10, "emqx@node2": 10}
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v3/brokers/":
+			w.Write([]byte(`{"code":0,"data":[{"node":"emqx@node1"},{"node":"emqx@node2"}]}`))
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/restart"):
+			conns[strings.Split(r.URL.Path, "/")[4]] = 0
+			w.Write([]byte(`{"code":0}`))
+		case strings.HasSuffix(r.URL.Path, "/listeners/"):
+			node := strings.Split(r.URL.Path, "/")[4]
+			polls++
+			fmt.Fprintf(w, `{"code":0,"data":[{"identifier":"mqtt:tcp:external","listen_on":"0.0.0.0:1883",`+
+				`"protocol":"mqtt:tcp","acceptors":16,"max_conns":1024,"current_conns":%d,`+
+				`"shutdown_count":{"closed":1}}]}`, conns[node])
+			// connections come back gradually after a restart
+			if conns[node] < 10 {
+				conns[node] += 5
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewAPIClient(ClientConfig{BaseURL: server.URL})
+	resp, err := c.ListNodeListeners("emqx@node1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := resp.Data[0]
+	if l.Identifier != "mqtt:tcp:external" || l.ListenOn != "0.0.0.0:1883" || l.MaxConns != 1024 ||
+		l.CurrentConns != 10 || l.ShutdownCount.Closed != 1 {
+		t.Fatalf("listener not decoded: %+v", l)
+	}
+
+	polls = 0
+	err = RollingRestartListener(context.Background(), c, "mqtt:tcp:external", RollingRestartOptions{
+		RecoverRatio: 1,
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// per node: before restart, then 0, 5 and 10 connections
+	if polls != 8 {
+		t.Fatalf("expected to wait for connections to recover, %d polls", polls)
+	}
+}
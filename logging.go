@@ -0,0 +1,198 @@
+package emqx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Logger structured logger, args are alternating keys and values.
+// *slog.Logger satisfies it, other loggers need a small adapter.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// redacted replace secrets in logs
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders headers never logged in clear
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveFields JSON fields never logged in clear, e.g. app secrets and user passwords
+var sensitiveFields = map[string]bool{
+	"secret":     true,
+	"app_secret": true,
+	"password":   true,
+	"old_pwd":    true,
+	"new_pwd":    true,
+	"token":      true,
+}
+
+// secretKey context key of the func returning the secret of a request, set by the transport
+type secretKey struct{}
+
+func withSecret(ctx context.Context, secret func() string) context.Context {
+	return context.WithValue(ctx, secretKey{}, secret)
+}
+
+// requestSecret the secret of the authenticator sending req, empty if unknown
+func requestSecret(req *http.Request) string {
+	if secret, ok := req.Context().Value(secretKey{}).(func() string); ok {
+		return secret()
+	}
+	return ""
+}
+
+// logMiddleware log request line, status and duration of every request with logger,
+// and headers and bodies at debug level if logBodies is set. Authorization headers,
+// secrets and passwords are redacted, as is the secret of the current authenticator.
+func logMiddleware(logger Logger, logBodies bool) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			secret := requestSecret(req)
+
+			if logBodies {
+				var body []byte
+				if req.Body != nil {
+					var err error
+					body, err = ioutil.ReadAll(req.Body)
+					req.Body.Close()
+					if err != nil {
+						return nil, err
+					}
+					req.Body = ioutil.NopCloser(bytes.NewReader(body))
+				}
+				logger.Debug("emqx request",
+					"method", req.Method,
+					"url", req.URL.String(),
+					"headers", redactHeaders(req.Header),
+					"body", redactBody(body, secret),
+				)
+			}
+
+			start := time.Now()
+			resp, err := next(req)
+			duration := time.Since(start)
+			if err != nil {
+				logger.Error("emqx request failed",
+					"method", req.Method,
+					"url", req.URL.String(),
+					"duration", duration,
+					"error", redactString(err.Error(), secret),
+				)
+				return nil, err
+			}
+
+			args := []interface{}{
+				"method", req.Method,
+				"url", req.URL.String(),
+				"status", resp.StatusCode,
+				"duration", duration,
+			}
+			if resp.StatusCode >= http.StatusBadRequest {
+				logger.Warn("emqx request", args...)
+			} else {
+				logger.Info("emqx request", args...)
+			}
+
+			if logBodies {
+				body, err := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+				resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+				logger.Debug("emqx response",
+					"method", req.Method,
+					"url", req.URL.String(),
+					"status", resp.StatusCode,
+					"headers", redactHeaders(resp.Header),
+					"body", redactBody(body, secret),
+				)
+			}
+			return resp, nil
+		}
+	}
+}
+
+// redactHeaders copy headers with sensitive values replaced
+func redactHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for k, v := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			headers[k] = redacted
+			continue
+		}
+		headers[k] = strings.Join(v, ", ")
+	}
+	return headers
+}
+
+// redactBody replace sensitive fields of a JSON body, and any occurrence of secret
+func redactBody(body []byte, secret string) string {
+	var v interface{}
+	if len(body) > 0 && json.Unmarshal(body, &v) == nil {
+		if b, err := json.Marshal(redactValue(v)); err == nil {
+			body = b
+		}
+	}
+	return redactString(string(body), secret)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if sensitiveFields[strings.ToLower(k)] {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactValue(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return v
+}
+
+func redactString(s, secret string) string {
+	if secret == "" {
+		return s
+	}
+	return strings.Replace(s, secret, redacted, -1)
+}
+
+// printLogger Logger printing to a *log.Logger, keys and values as key=value
+type printLogger struct {
+	l *log.Logger
+}
+
+func (p printLogger) Debug(msg string, args ...interface{}) { p.print("DEBUG", msg, args) }
+func (p printLogger) Info(msg string, args ...interface{})  { p.print("INFO", msg, args) }
+func (p printLogger) Warn(msg string, args ...interface{})  { p.print("WARN", msg, args) }
+func (p printLogger) Error(msg string, args ...interface{}) { p.print("ERROR", msg, args) }
+
+func (p printLogger) print(level, msg string, args []interface{}) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s %s", level, msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	p.l.Print(b.String())
+}
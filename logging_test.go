@@ -0,0 +1,79 @@
+package emqx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) log(level, msg string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprint(level, " ", msg, " ", args))
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("DEBUG", msg, args...) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args...) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("WARN", msg, args...) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args...) }
+
+func TestLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/apps/" {
+			w.Write([]byte(`{"code":0,"data":{"secret":"generated"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`invalid secret topsecret`))
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+	c := NewAPIClient(ClientConfig{
+		BaseURL:   server.URL,
+		AppID:     "app",
+		AppSecret: "topsecret",
+		Logger:    logger,
+		LogBodies: true,
+	})
+	c.CreateUser(&CreateUserRequestV3{Username: "u1", Password: "p4ssw0rd"})
+	c.CreateApp(&CreateAppRequestV3{AppID: "app2", Name: "app2"})
+
+	out := strings.Join(logger.lines, "\n")
+	for _, secret := range []string{"topsecret", "p4ssw0rd", "generated", basicToken("app", "topsecret")} {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %s logged:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "WARN emqx request") || !strings.Contains(out, "status 401") {
+		t.Errorf("unauthorized request not logged:\n%s", out)
+	}
+	if !strings.Contains(out, `"username":"u1"`) {
+		t.Errorf("request body not logged:\n%s", out)
+	}
+}
+
+func TestLoggerRotatedSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`invalid secret rotated`))
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+	c := NewAPIClient(ClientConfig{BaseURL: server.URL, AppID: "app", AppSecret: "initial", Logger: logger, LogBodies: true})
+	c.SetCredentials("app", "rotated")
+	c.ListCluster()
+
+	if out := strings.Join(logger.lines, "\n"); strings.Contains(out, "rotated") {
+		t.Errorf("rotated secret logged:\n%s", out)
+	}
+}
@@ -0,0 +1,40 @@
+package emqx
+
+import (
+	"log"
+	"net/http"
+)
+
+// RoundTripFunc send a request and return its response
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wrap the round trip of every request, e.g. to add headers or record latency.
+// Middlewares see the request after Authorization and Content-Type are set.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// chain wrap rt with middlewares, the first middleware is the outermost
+func chain(rt RoundTripFunc, middlewares []Middleware) RoundTripFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// HeaderMiddleware set custom headers on every request, e.g. tracing headers
+func HeaderMiddleware(headers http.Header) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			for k, v := range headers {
+				req.Header[http.CanonicalHeaderKey(k)] = v
+			}
+			return next(req)
+		}
+	}
+}
+
+// LoggingMiddleware log request line, status and latency of every request to logger,
+// and request and response bodies if logBodies is set. Secrets and passwords are redacted
+// as with ClientConfig.Logger, including the secret of the client's current authenticator.
+func LoggingMiddleware(logger *log.Logger, logBodies bool) Middleware {
+	return logMiddleware(printLogger{logger}, logBodies)
+}
@@ -0,0 +1,71 @@
+package emqx
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewares(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace-Id") != "trace" || r.Header.Get("Authorization") == "" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	record := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next(req)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	a := NewAPIClient(ClientConfig{
+		BaseURL: server.URL,
+		Middlewares: []Middleware{
+			record("outer"),
+			HeaderMiddleware(http.Header{"X-Trace-Id": []string{"trace"}}),
+			LoggingMiddleware(log.New(&buf, "", 0), true),
+			record("inner"),
+		},
+	})
+	if _, err := a.ListCluster(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Fatal(order)
+	}
+	if !strings.Contains(buf.String(), "method=GET url="+server.URL+"/api/v3/brokers/ status=200") || !strings.Contains(buf.String(), `{"code":0}`) {
+		t.Fatal(buf.String())
+	}
+}
+
+func TestLoggingMiddlewareRedacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`invalid secret rotated`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	a := NewAPIClient(ClientConfig{
+		BaseURL:     server.URL,
+		AppID:       "app",
+		AppSecret:   "initial",
+		Middlewares: []Middleware{LoggingMiddleware(log.New(&buf, "", 0), true)},
+	})
+	a.SetCredentials("app", "rotated")
+	a.ListCluster()
+	if strings.Contains(buf.String(), "rotated") || !strings.Contains(buf.String(), "status=401") {
+		t.Fatal(buf.String())
+	}
+}
@@ -1,5 +1,10 @@
 package emqx
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
 // NoContentResponse no content
 type NoContentResponse struct {
 	Code    int
@@ -27,13 +32,13 @@ type ListAPIResponseV3 struct {
 
 // ClusterV3 cluster info
 type ClusterV3 struct {
-	Datetime   string
-	Node       string
-	NodeStatus string
-	OtpRelease string
-	Sysdescr   string
-	Uptime     string
-	Version    string
+	Datetime   string `json:"datetime"`
+	Node       string `json:"node"`
+	NodeStatus string `json:"node_status"`
+	OtpRelease string `json:"otp_release"`
+	Sysdescr   string `json:"sysdescr"`
+	Uptime     string `json:"uptime"`
+	Version    string `json:"version"`
 }
 
 // ListClusterResponseV3 - List all Cluster
@@ -52,21 +57,21 @@ type NodeInfoResponseV3 struct {
 
 // NodeStatV3 node stat
 type NodeStatV3 struct {
-	Connections      int
-	Load1            string
-	Load15           string
-	Load5            string
-	MaxFds           int
-	MemoryTotal      string
-	MemoryUsed       string
-	Name             string
-	Node             string
-	NodeStatus       string
-	OtpRelease       string
-	ProcessAvailable int
-	ProcessUsed      int
-	Uptime           string
-	Version          string
+	Connections      int    `json:"connections"`
+	Load1            string `json:"load1"`
+	Load15           string `json:"load15"`
+	Load5            string `json:"load5"`
+	MaxFds           int    `json:"max_fds"`
+	MemoryTotal      string `json:"memory_total"`
+	MemoryUsed       string `json:"memory_used"`
+	Name             string `json:"name"`
+	Node             string `json:"node"`
+	NodeStatus       string `json:"node_status"`
+	OtpRelease       string `json:"otp_release"`
+	ProcessAvailable int    `json:"process_available"`
+	ProcessUsed      int    `json:"process_used"`
+	Uptime           string `json:"uptime"`
+	Version          string `json:"version"`
 }
 
 // ListNodeStatResponseV3 - List Statistics of All Nodes in the Cluster
@@ -145,6 +150,40 @@ type NodeConnectionResponseV3 struct {
 	Data []ConnectionV3
 }
 
+// ListConnectionsByUsernameResponseV3 - List Connections of a Username in the Cluster
+// GET api/v3/connections/username/${username}
+type ListConnectionsByUsernameResponseV3 struct {
+	Code int
+	Data []ConnectionV3
+}
+
+// ListNodeConnectionsByUsernameResponseV3 - List Connections of a Username on a node
+// GET api/v3/nodes/${node}/connections/username/${username}
+type ListNodeConnectionsByUsernameResponseV3 struct {
+	Code int
+	Data []ConnectionV3
+}
+
+//
+// ACL Cache
+//
+
+// ACLCacheEntryV3 cached ACL decision of a connection
+type ACLCacheEntryV3 struct {
+	Access      string `json:"access"`
+	Topic       string `json:"topic"`
+	Result      string `json:"result"`
+	UpdatedTime int64  `json:"updated_time"`
+}
+
+// ListACLCacheResponseV3 - List ACL cache of a Connection
+// GET api/v3/connections/${clientid}/acl_cache
+// GET api/v3/nodes/${node}/connections/${clientid}/acl_cache
+type ListACLCacheResponseV3 struct {
+	Code int
+	Data []ACLCacheEntryV3
+}
+
 //
 // Sessions
 //
@@ -202,6 +241,20 @@ type GetNodeSessionResponseV3 struct {
 	Data []SessionV3
 }
 
+// ListSessionsByUsernameResponseV3 - List Sessions of a Username in the Cluster
+// GET api/v3/sessions/username/${username}
+type ListSessionsByUsernameResponseV3 struct {
+	Code int
+	Data []SessionV3
+}
+
+// ListNodeSessionsByUsernameResponseV3 - List Sessions of a Username on a Node
+// GET api/v3/nodes/${node}/sessions/username/${username}
+type ListNodeSessionsByUsernameResponseV3 struct {
+	Code int
+	Data []SessionV3
+}
+
 //
 // Subscriptions
 //
@@ -327,6 +380,57 @@ type ListNodePluginResponseV3 struct {
 	Data []PluginV3
 }
 
+// ConfigItemV3 config item
+type ConfigItemV3 struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Desc     string      `json:"desc"`
+	Required bool        `json:"required"`
+	Type     string      `json:"type"`
+}
+
+// GetPluginConfigResponseV3 get config of a plugin
+// GET api/v3/nodes/${node}/plugins/${plugin}
+// GET api/v3/configs/${plugin}
+type GetPluginConfigResponseV3 struct {
+	Code int
+	Data []ConfigItemV3
+}
+
+// Map convert config items to key value form
+func (r *GetPluginConfigResponseV3) Map() map[string]interface{} {
+	return configItemsToMap(r.Data)
+}
+
+//
+// Configs
+//
+
+// NodeConfigsV3 configs list of a node
+type NodeConfigsV3 struct {
+	Node    string         `json:"node"`
+	Configs []ConfigItemV3 `json:"configs"`
+}
+
+// ListConfigsResponseV3 list all configs of cluster
+// GET api/v3/configs/
+type ListConfigsResponseV3 struct {
+	Code int
+	Data []NodeConfigsV3
+}
+
+// GetNodeConfigsResponseV3 list all configs of a node
+// GET api/v3/nodes/${node}/configs/
+type GetNodeConfigsResponseV3 struct {
+	Code int
+	Data []ConfigItemV3
+}
+
+// Map convert config items to key value form
+func (r *GetNodeConfigsResponseV3) Map() map[string]interface{} {
+	return configItemsToMap(r.Data)
+}
+
 //
 // Listeners
 //
@@ -339,12 +443,13 @@ type ShutdownCountV3 struct {
 
 // ListenerV3 listener info
 type ListenerV3 struct {
-	Acceptors     int
-	CurrentConns  int
-	ListenOn      string
-	MaxConns      int
-	Protocol      string
-	ShutdownCount ShutdownCountV3
+	Identifier    string          `json:"identifier"`
+	Acceptors     int             `json:"acceptors"`
+	CurrentConns  int             `json:"current_conns"`
+	ListenOn      string          `json:"listen_on"`
+	MaxConns      int             `json:"max_conns"`
+	Protocol      string          `json:"protocol"`
+	ShutdownCount ShutdownCountV3 `json:"shutdown_count"`
 }
 
 // NodeListenersV3 listeners info list of a node
@@ -437,3 +542,180 @@ type GetNodeMetricsResponseV3 struct {
 	Code int
 	Data MetricsV3
 }
+
+// StatsV3 statistics of a node, e.g. connections/count, sessions/max
+type StatsV3 map[string]int64
+
+// NodeStatsV3 statistics of a node
+type NodeStatsV3 struct {
+	Node  string  `json:"node"`
+	Stats StatsV3 `json:"stats"`
+}
+
+// ListClusterStatsResponseV3 list statistics of the cluster
+// GET api/v3/stats/
+type ListClusterStatsResponseV3 struct {
+	Code int
+	Data []NodeStatsV3
+}
+
+//
+// Alarms
+//
+
+// AlarmV3 alarm raised by a node, e.g. high system memory usage
+type AlarmV3 struct {
+	ID   string `json:"id"`
+	Desc string `json:"desc"`
+}
+
+// NodeAlarmsV3 present alarms of a node
+type NodeAlarmsV3 struct {
+	Node   string    `json:"node"`
+	Alarms []AlarmV3 `json:"alarms"`
+}
+
+// ListClusterAlarmsResponseV3 list present alarms of the cluster
+// GET api/v3/alarms/present
+type ListClusterAlarmsResponseV3 struct {
+	Code int
+	Data []NodeAlarmsV3
+}
+
+// ListNodeAlarmsResponseV3 list present alarms of a node
+// GET api/v3/alarms/present/${node}
+type ListNodeAlarmsResponseV3 struct {
+	Code int
+	Data []AlarmV3
+}
+
+//
+// Applications
+//
+
+// ExpiryV3 application expiry as unix timestamp in seconds, 0 means never expire
+type ExpiryV3 int64
+
+// MarshalJSON encode never expire as undefined
+func (e ExpiryV3) MarshalJSON() ([]byte, error) {
+	if e == 0 {
+		return []byte(`"undefined"`), nil
+	}
+	return []byte(strconv.FormatInt(int64(e), 10)), nil
+}
+
+// UnmarshalJSON decode expiry from timestamp or undefined
+func (e *ExpiryV3) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*e = 0
+		return nil
+	}
+	var v int64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*e = ExpiryV3(v)
+	return nil
+}
+
+// AppV3 application info
+type AppV3 struct {
+	AppID   string   `json:"app_id"`
+	Name    string   `json:"name"`
+	Secret  string   `json:"secret"`
+	Desc    string   `json:"desc"`
+	Status  bool     `json:"status"`
+	Expired ExpiryV3 `json:"expired"`
+}
+
+// ListAppsResponseV3 list all applications
+// GET api/v3/apps/
+type ListAppsResponseV3 struct {
+	Code int
+	Data []AppV3
+}
+
+// GetAppResponseV3 retrieve an application
+// GET api/v3/apps/${appid}
+type GetAppResponseV3 struct {
+	Code int
+	Data AppV3
+}
+
+// CreateAppRequestV3 create an application, secret is generated by EMQX if empty
+// POST api/v3/apps/
+type CreateAppRequestV3 struct {
+	AppID   string   `json:"app_id"`
+	Name    string   `json:"name"`
+	Secret  string   `json:"secret,omitempty"`
+	Desc    string   `json:"desc"`
+	Status  bool     `json:"status"`
+	Expired ExpiryV3 `json:"expired"`
+}
+
+// CreatedAppV3 created application
+type CreatedAppV3 struct {
+	Secret string `json:"secret"`
+}
+
+// CreateAppResponseV3 create an application
+// POST api/v3/apps/
+type CreateAppResponseV3 struct {
+	Code    int
+	Message string
+	Data    CreatedAppV3
+}
+
+// UpdateAppRequestV3 update an application
+// PUT api/v3/apps/${appid}
+type UpdateAppRequestV3 struct {
+	Name    string   `json:"name"`
+	Desc    string   `json:"desc"`
+	Status  bool     `json:"status"`
+	Expired ExpiryV3 `json:"expired"`
+}
+
+//
+// Users
+//
+
+// AuthUserRequestV3 authenticate a dashboard user
+// POST api/v3/auth
+type AuthUserRequestV3 struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// UserV3 dashboard user
+type UserV3 struct {
+	Username string `json:"username"`
+	Tags     string `json:"tags"`
+}
+
+// ListUsersResponseV3 list all dashboard users
+// GET api/v3/users/
+type ListUsersResponseV3 struct {
+	Code int
+	Data []UserV3
+}
+
+// CreateUserRequestV3 create a dashboard user
+// POST api/v3/users/
+type CreateUserRequestV3 struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Tags     string `json:"tags"`
+}
+
+// UpdateUserRequestV3 update a dashboard user
+// PUT api/v3/users/${username}
+type UpdateUserRequestV3 struct {
+	Tags string `json:"tags"`
+}
+
+// ChangeUserPasswordRequestV3 change password of a dashboard user
+// PUT api/v3/change_pwd/${username}
+type ChangeUserPasswordRequestV3 struct {
+	OldPassword string `json:"old_pwd"`
+	NewPassword string `json:"new_pwd"`
+}
@@ -0,0 +1,350 @@
+package emqx
+
+// PageV4 pagination of list requests, zero values use the server defaults
+type PageV4 struct {
+	Page  int
+	Limit int
+}
+
+// MetaV4 pagination info of list responses
+type MetaV4 struct {
+	Page    int  `json:"page"`
+	Limit   int  `json:"limit"`
+	Count   int  `json:"count"`
+	HasNext bool `json:"hasnext"`
+}
+
+//
+// Cluster & Node
+//
+
+// APIV4 api info
+type APIV4 struct {
+	Name   string
+	Path   string
+	Descr  string
+	Method string
+}
+
+// ListAPIResponseV4 - List all API describe
+// GET api/v4/
+type ListAPIResponseV4 struct {
+	Code int
+	Data []APIV4
+}
+
+// ClusterV4 cluster info
+type ClusterV4 struct {
+	Datetime   string `json:"datetime"`
+	Node       string `json:"node"`
+	NodeStatus string `json:"node_status"`
+	OtpRelease string `json:"otp_release"`
+	Sysdescr   string `json:"sysdescr"`
+	Uptime     string `json:"uptime"`
+	Version    string `json:"version"`
+}
+
+// ListClusterResponseV4 - List all Cluster
+// GET api/v4/brokers/
+type ListClusterResponseV4 struct {
+	Code int
+	Data []ClusterV4
+}
+
+// NodeInfoResponseV4 -  Retrieve Info of a Node
+// GET api/v4/brokers/${node}
+type NodeInfoResponseV4 struct {
+	Code int
+	Data ClusterV4
+}
+
+// NodeStatV4 node stat
+type NodeStatV4 struct {
+	Connections      int    `json:"connections"`
+	Load1            string `json:"load1"`
+	Load15           string `json:"load15"`
+	Load5            string `json:"load5"`
+	MaxFds           int    `json:"max_fds"`
+	MemoryTotal      string `json:"memory_total"`
+	MemoryUsed       string `json:"memory_used"`
+	Name             string `json:"name"`
+	Node             string `json:"node"`
+	NodeStatus       string `json:"node_status"`
+	OtpRelease       string `json:"otp_release"`
+	ProcessAvailable int    `json:"process_available"`
+	ProcessUsed      int    `json:"process_used"`
+	Uptime           string `json:"uptime"`
+	Version          string `json:"version"`
+}
+
+// ListNodeStatResponseV4 - List Statistics of All Nodes in the Cluster
+// GET api/v4/nodes/
+type ListNodeStatResponseV4 struct {
+	Code int
+	Data []NodeStatV4
+}
+
+// NodeStatResponseV4 - Retrieve Statistics of a Specific Node
+// GET api/v4/nodes/${node}
+type NodeStatResponseV4 struct {
+	Code int
+	Data NodeStatV4
+}
+
+//
+// Clients
+//
+
+// ConnectionV4 client info, v4 merges v3 connections and sessions into clients
+type ConnectionV4 struct {
+	ClientID         string `json:"clientid"`
+	Username         string `json:"username"`
+	Node             string `json:"node"`
+	Connected        bool   `json:"connected"`
+	ConnectedAt      string `json:"connected_at"`
+	DisconnectedAt   string `json:"disconnected_at"`
+	CreatedAt        string `json:"created_at"`
+	IPAddress        string `json:"ip_address"`
+	Port             int    `json:"port"`
+	ProtoName        string `json:"proto_name"`
+	ProtoVer         int    `json:"proto_ver"`
+	KeepAlive        int    `json:"keepalive"`
+	CleanStart       bool   `json:"clean_start"`
+	ExpiryInterval   int    `json:"expiry_interval"`
+	IsBridge         bool   `json:"is_bridge"`
+	Zone             string `json:"zone"`
+	RecvCnt          int    `json:"recv_cnt"`
+	RecvMsg          int    `json:"recv_msg"`
+	RecvOct          int    `json:"recv_oct"`
+	RecvPkt          int    `json:"recv_pkt"`
+	SendCnt          int    `json:"send_cnt"`
+	SendMsg          int    `json:"send_msg"`
+	SendOct          int    `json:"send_oct"`
+	SendPkt          int    `json:"send_pkt"`
+	MailboxLen       int    `json:"mailbox_len"`
+	HeapSize         int    `json:"heap_size"`
+	Reductions       int    `json:"reductions"`
+	SubscriptionsCnt int    `json:"subscriptions_cnt"`
+	MaxSubscriptions int    `json:"max_subscriptions"`
+	Inflight         int    `json:"inflight"`
+	MaxInflight      int    `json:"max_inflight"`
+	MqueueLen        int    `json:"mqueue_len"`
+	MaxMqueue        int    `json:"max_mqueue"`
+	MqueueDropped    int    `json:"mqueue_dropped"`
+	AwaitingRel      int    `json:"awaiting_rel"`
+	MaxAwaitingRel   int    `json:"max_awaiting_rel"`
+}
+
+// ListClientsResponseV4 - List all Clients
+// GET api/v4/clients
+// GET api/v4/nodes/${node}/clients
+type ListClientsResponseV4 struct {
+	Code int
+	Data []ConnectionV4
+	Meta MetaV4
+}
+
+// GetClientResponseV4 - Retrieve a Client
+// GET api/v4/clients/${clientid}
+// GET api/v4/nodes/${node}/clients/${clientid}
+// GET api/v4/clients/username/${username}
+type GetClientResponseV4 struct {
+	Code int
+	Data []ConnectionV4
+}
+
+// ACLCacheEntryV4 cached ACL decision of a client
+type ACLCacheEntryV4 struct {
+	Access      string `json:"access"`
+	Topic       string `json:"topic"`
+	Result      string `json:"result"`
+	UpdatedTime int64  `json:"updated_time"`
+}
+
+// ListACLCacheResponseV4 - List ACL cache of a Client
+// GET api/v4/clients/${clientid}/acl_cache
+type ListACLCacheResponseV4 struct {
+	Code int
+	Data []ACLCacheEntryV4
+}
+
+//
+// Subscriptions
+//
+
+// SubscriptionV4 subscription
+type SubscriptionV4 struct {
+	ClientID string `json:"clientid"`
+	Node     string `json:"node"`
+	Qos      int    `json:"qos"`
+	Topic    string `json:"topic"`
+}
+
+// ListSubscriptionsResponseV4 - List all Subscriptions
+// GET api/v4/subscriptions
+// GET api/v4/nodes/${node}/subscriptions
+type ListSubscriptionsResponseV4 struct {
+	Code int
+	Data []SubscriptionV4
+	Meta MetaV4
+}
+
+// ListClientSubscriptionsResponseV4 - List Subscriptions of a Client in the Cluster
+// GET api/v4/subscriptions/${clientid}
+type ListClientSubscriptionsResponseV4 struct {
+	Code int
+	Data []SubscriptionV4
+}
+
+//
+// Routes
+//
+
+// RouteV4 route
+type RouteV4 struct {
+	Node  string `json:"node"`
+	Topic string `json:"topic"`
+}
+
+// ListRoutesResponseV4 - List all Routes in the Cluster
+// GET api/v4/routes
+type ListRoutesResponseV4 struct {
+	Code int
+	Data []RouteV4
+	Meta MetaV4
+}
+
+// GetTopicRoutesResponseV4 - Retrieve a Route of Topic in the Cluster
+// GET api/v4/routes/${topic}
+type GetTopicRoutesResponseV4 struct {
+	Code int
+	Data []RouteV4
+}
+
+//
+// Publish/Subscribe
+//
+
+// PublishMessageRequestV4 Publish message request
+// POST api/v4/mqtt/publish
+type PublishMessageRequestV4 struct {
+	Topic    string `json:"topic"`
+	Payload  string `json:"payload"`
+	Encoding string `json:"encoding,omitempty"`
+	Qos      int    `json:"qos"`
+	Retain   bool   `json:"retain"`
+	ClientID string `json:"clientid"`
+}
+
+// CreateSubscriptionRequestV4 create subscription
+// POST api/v4/mqtt/subscribe
+type CreateSubscriptionRequestV4 struct {
+	Topic    string `json:"topic"`
+	Qos      int    `json:"qos"`
+	ClientID string `json:"clientid"`
+}
+
+// UnSubscribeRequestV4 unsubscribe
+// POST api/v4/mqtt/unsubscribe
+type UnSubscribeRequestV4 struct {
+	Topic    string `json:"topic"`
+	ClientID string `json:"clientid"`
+}
+
+//
+// Plugins
+//
+
+// PluginV4 plugin detail
+type PluginV4 struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Active      bool   `json:"active"`
+	Type        string `json:"type"`
+}
+
+// NodePluginV4 plugins info list of a node
+type NodePluginV4 struct {
+	Node    string     `json:"node"`
+	Plugins []PluginV4 `json:"plugins"`
+}
+
+// ListClusterPluginResponseV4 list all plugins of cluster
+// GET api/v4/plugins/
+type ListClusterPluginResponseV4 struct {
+	Code int
+	Data []NodePluginV4
+}
+
+// ListNodePluginResponseV4 list all plugins of a node
+// GET api/v4/nodes/${node}/plugins/
+type ListNodePluginResponseV4 struct {
+	Code int
+	Data []PluginV4
+}
+
+//
+// Listeners
+//
+
+// ShutdownCountV4 listener shutdown count
+type ShutdownCountV4 map[string]int
+
+// ListenerV4 listener info
+type ListenerV4 struct {
+	Identifier    string          `json:"identifier"`
+	Protocol      string          `json:"protocol"`
+	ListenOn      string          `json:"listen_on"`
+	Acceptors     int             `json:"acceptors"`
+	MaxConns      int             `json:"max_conns"`
+	CurrentConns  int             `json:"current_conns"`
+	ShutdownCount ShutdownCountV4 `json:"shutdown_count"`
+}
+
+// NodeListenersV4 listeners info list of a node
+type NodeListenersV4 struct {
+	Node      string       `json:"node"`
+	Listeners []ListenerV4 `json:"listeners"`
+}
+
+// ListClusterListenersResponseV4 list all listeners of cluster
+// GET api/v4/listeners/
+type ListClusterListenersResponseV4 struct {
+	Code int
+	Data []NodeListenersV4
+}
+
+// ListNodeListenerResponseV4 list all listeners of a node
+// GET api/v4/nodes/${node}/listeners
+type ListNodeListenerResponseV4 struct {
+	Code int
+	Data []ListenerV4
+}
+
+//
+// Metrics
+//
+
+// MetricsV4 metrics keyed by name, e.g. messages/received
+type MetricsV4 map[string]int64
+
+// NodeMetricsV4 metrics of a node
+type NodeMetricsV4 struct {
+	Node    string    `json:"node"`
+	Metrics MetricsV4 `json:"metrics"`
+}
+
+// ListClusterMetricsResponseV4 list metrics of the cluster
+// GET api/v4/metrics/
+type ListClusterMetricsResponseV4 struct {
+	Code int
+	Data []NodeMetricsV4
+}
+
+// GetNodeMetricsResponseV4 get all metrics of a node
+// GET api/v4/nodes/${node}/metrics
+type GetNodeMetricsResponseV4 struct {
+	Code int
+	Data MetricsV4
+}
@@ -0,0 +1,245 @@
+package emqx
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ErrorResponseV5 error returned by EMQX v5, e.g. {"code": "NOT_FOUND", "message": "Client ID not found"}
+type ErrorResponseV5 struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *ErrorResponseV5) Error() string {
+	return fmt.Sprintf("emqx: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// MetaV5 pagination info of list responses
+type MetaV5 struct {
+	Page    int  `json:"page"`
+	Limit   int  `json:"limit"`
+	Count   int  `json:"count"`
+	HasNext bool `json:"hasnext"`
+}
+
+//
+// Nodes
+//
+
+// NodeV5 node info
+type NodeV5 struct {
+	Node             string  `json:"node"`
+	NodeStatus       string  `json:"node_status"`
+	Role             string  `json:"role"`
+	Version          string  `json:"version"`
+	Edition          string  `json:"edition"`
+	OtpRelease       string  `json:"otp_release"`
+	Uptime           int64   `json:"uptime"`
+	Connections      int     `json:"connections"`
+	LiveConnections  int     `json:"live_connections"`
+	Load1            float64 `json:"load1"`
+	Load5            float64 `json:"load5"`
+	Load15           float64 `json:"load15"`
+	MaxFds           int     `json:"max_fds"`
+	MemoryTotal      int64   `json:"memory_total"`
+	MemoryUsed       int64   `json:"memory_used"`
+	ProcessAvailable int     `json:"process_available"`
+	ProcessUsed      int     `json:"process_used"`
+	SysPath          string  `json:"sys_path"`
+	LogPath          string  `json:"log_path"`
+}
+
+// StatsV5 statistics keyed by name, e.g. connections.count
+type StatsV5 map[string]int64
+
+// UnmarshalJSON decode numeric statistics, non numeric fields such as node are skipped
+func (s *StatsV5) UnmarshalJSON(data []byte) error {
+	m, err := decodeCountersV5(data)
+	*s = StatsV5(m)
+	return err
+}
+
+// MetricsV5 metrics keyed by name, e.g. messages.received
+type MetricsV5 map[string]int64
+
+// UnmarshalJSON decode numeric metrics, non numeric fields such as node are skipped
+func (m *MetricsV5) UnmarshalJSON(data []byte) error {
+	c, err := decodeCountersV5(data)
+	*m = MetricsV5(c)
+	return err
+}
+
+func decodeCountersV5(data []byte) (map[string]int64, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	m := make(map[string]int64, len(raw))
+	for k, v := range raw {
+		if f, ok := v.(float64); ok {
+			m[k] = int64(f)
+		}
+	}
+	return m, nil
+}
+
+//
+// Clients
+//
+
+// ClientInfoV5 client info
+type ClientInfoV5 struct {
+	ClientID         string `json:"clientid"`
+	Username         string `json:"username"`
+	Node             string `json:"node"`
+	Connected        bool   `json:"connected"`
+	ConnectedAt      string `json:"connected_at"`
+	DisconnectedAt   string `json:"disconnected_at"`
+	CreatedAt        string `json:"created_at"`
+	IPAddress        string `json:"ip_address"`
+	Port             int    `json:"port"`
+	Listener         string `json:"listener"`
+	ProtoName        string `json:"proto_name"`
+	ProtoVer         int    `json:"proto_ver"`
+	KeepAlive        int    `json:"keepalive"`
+	CleanStart       bool   `json:"clean_start"`
+	ExpiryInterval   int    `json:"expiry_interval"`
+	IsBridge         bool   `json:"is_bridge"`
+	Mountpoint       string `json:"mountpoint"`
+	RecvCnt          int    `json:"recv_cnt"`
+	RecvMsg          int    `json:"recv_msg"`
+	RecvOct          int    `json:"recv_oct"`
+	RecvPkt          int    `json:"recv_pkt"`
+	SendCnt          int    `json:"send_cnt"`
+	SendMsg          int    `json:"send_msg"`
+	SendOct          int    `json:"send_oct"`
+	SendPkt          int    `json:"send_pkt"`
+	MailboxLen       int    `json:"mailbox_len"`
+	HeapSize         int    `json:"heap_size"`
+	Reductions       int    `json:"reductions"`
+	SubscriptionsCnt int    `json:"subscriptions_cnt"`
+	SubscriptionsMax int    `json:"subscriptions_max"`
+	InflightCnt      int    `json:"inflight_cnt"`
+	InflightMax      int    `json:"inflight_max"`
+	MqueueLen        int    `json:"mqueue_len"`
+	MqueueMax        int    `json:"mqueue_max"`
+	MqueueDropped    int    `json:"mqueue_dropped"`
+	AwaitingRelCnt   int    `json:"awaiting_rel_cnt"`
+	AwaitingRelMax   int    `json:"awaiting_rel_max"`
+}
+
+// ListClientsQueryV5 filters of listing clients, zero values are ignored
+type ListClientsQueryV5 struct {
+	Page      int
+	Limit     int
+	Node      string
+	Username  string
+	ConnState string
+}
+
+// ListClientsResponseV5 - List Clients in the Cluster
+// GET api/v5/clients
+type ListClientsResponseV5 struct {
+	Data []ClientInfoV5 `json:"data"`
+	Meta MetaV5         `json:"meta"`
+}
+
+//
+// Subscriptions
+//
+
+// SubscriptionV5 subscription
+type SubscriptionV5 struct {
+	ClientID string `json:"clientid"`
+	Node     string `json:"node"`
+	Topic    string `json:"topic"`
+	Qos      int    `json:"qos"`
+	Nl       int    `json:"nl"`
+	Rap      int    `json:"rap"`
+	Rh       int    `json:"rh"`
+}
+
+// SubscribeRequestV5 subscribe a client to a topic
+// POST api/v5/clients/${clientid}/subscribe
+type SubscribeRequestV5 struct {
+	Topic string `json:"topic"`
+	Qos   int    `json:"qos"`
+	Nl    int    `json:"nl,omitempty"`
+	Rap   int    `json:"rap,omitempty"`
+	Rh    int    `json:"rh,omitempty"`
+}
+
+// UnsubscribeRequestV5 unsubscribe a client from a topic
+// POST api/v5/clients/${clientid}/unsubscribe
+type UnsubscribeRequestV5 struct {
+	Topic string `json:"topic"`
+}
+
+// ListSubscriptionsQueryV5 filters of listing subscriptions, zero values are ignored
+type ListSubscriptionsQueryV5 struct {
+	Page       int
+	Limit      int
+	Node       string
+	ClientID   string
+	MatchTopic string
+}
+
+// ListSubscriptionsResponseV5 - List Subscriptions in the Cluster
+// GET api/v5/subscriptions
+type ListSubscriptionsResponseV5 struct {
+	Data []SubscriptionV5 `json:"data"`
+	Meta MetaV5           `json:"meta"`
+}
+
+//
+// Publish
+//
+
+// PublishMessageRequestV5 Publish message request
+// POST api/v5/publish
+type PublishMessageRequestV5 struct {
+	Topic           string                 `json:"topic"`
+	Payload         string                 `json:"payload"`
+	PayloadEncoding string                 `json:"payload_encoding,omitempty"`
+	Qos             int                    `json:"qos"`
+	Retain          bool                   `json:"retain"`
+	ClientID        string                 `json:"clientid,omitempty"`
+	Properties      map[string]interface{} `json:"properties,omitempty"`
+}
+
+// PublishMessageResponseV5 Publish message result
+// POST api/v5/publish
+type PublishMessageResponseV5 struct {
+	ID string `json:"id"`
+}
+
+//
+// Authentication
+//
+
+// AuthenticationSourceV5 authenticator config of the authentication chain.
+// The schema depends on mechanism and backend, so it is kept in generic form.
+type AuthenticationSourceV5 map[string]interface{}
+
+// ID authenticator id, e.g. password_based:built_in_database
+func (a AuthenticationSourceV5) ID() string {
+	return configString(a, "id")
+}
+
+// Mechanism authentication mechanism, e.g. password_based, jwt
+func (a AuthenticationSourceV5) Mechanism() string {
+	return configString(a, "mechanism")
+}
+
+// Backend authentication backend, e.g. built_in_database, http
+func (a AuthenticationSourceV5) Backend() string {
+	return configString(a, "backend")
+}
+
+// Enabled whether the authenticator is enabled, EMQX defaults to enabled
+func (a AuthenticationSourceV5) Enabled() bool {
+	enable, ok := a["enable"].(bool)
+	return !ok || enable
+}
@@ -0,0 +1,177 @@
+package emqx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Plugin names with typed config helpers
+const (
+	PluginWebHook  = "emqx_web_hook"
+	PluginAuthHTTP = "emqx_auth_http"
+)
+
+const (
+	webHookAPIURLKey     = "web.hook.api.url"
+	webHookEncodeKey     = "web.hook.encode_payload"
+	webHookRulePrefix    = "web.hook.rule."
+	authHTTPAuthReqKey   = "auth.http.auth_req"
+	authHTTPSuperReqKey  = "auth.http.super_req"
+	authHTTPACLReqKey    = "auth.http.acl_req"
+	authHTTPTimeoutKey   = "auth.http.request.timeout"
+	authHTTPMethodSuffix = ".method"
+	authHTTPParamsSuffix = ".params"
+)
+
+// WebHookConfigV3 config of emqx_web_hook plugin
+type WebHookConfigV3 struct {
+	// APIURL web.hook.api.url
+	APIURL string
+	// EncodePayload web.hook.encode_payload, e.g. base64 or base62
+	EncodePayload string
+	// Rules web.hook.rule.* entries, keyed without the web.hook.rule. prefix,
+	// e.g. client.connected.1 => {"action": "on_client_connected"}
+	Rules map[string]string
+}
+
+// Map convert to the generic config form, empty fields are left out so partial updates keep them
+func (c *WebHookConfigV3) Map() map[string]interface{} {
+	m := map[string]interface{}{}
+	if c.APIURL != "" {
+		m[webHookAPIURLKey] = c.APIURL
+	}
+	if c.EncodePayload != "" {
+		m[webHookEncodeKey] = c.EncodePayload
+	}
+	for k, v := range c.Rules {
+		m[webHookRulePrefix+k] = v
+	}
+	return m
+}
+
+// NewWebHookConfigV3 parse emqx_web_hook config from the generic config form
+func NewWebHookConfigV3(m map[string]interface{}) *WebHookConfigV3 {
+	c := &WebHookConfigV3{
+		APIURL:        configString(m, webHookAPIURLKey),
+		EncodePayload: configString(m, webHookEncodeKey),
+		Rules:         map[string]string{},
+	}
+	for k := range m {
+		if strings.HasPrefix(k, webHookRulePrefix) {
+			c.Rules[strings.TrimPrefix(k, webHookRulePrefix)] = configString(m, k)
+		}
+	}
+	return c
+}
+
+// AuthHTTPRequestV3 request setting of emqx_auth_http plugin
+type AuthHTTPRequestV3 struct {
+	// URL request url
+	URL string
+	// Method request method, get or post
+	Method string
+	// Params request params, e.g. clientid=%c,username=%u,password=%P
+	Params string
+}
+
+// AuthHTTPConfigV3 config of emqx_auth_http plugin
+type AuthHTTPConfigV3 struct {
+	// AuthReq auth.http.auth_req
+	AuthReq AuthHTTPRequestV3
+	// SuperReq auth.http.super_req
+	SuperReq AuthHTTPRequestV3
+	// ACLReq auth.http.acl_req
+	ACLReq AuthHTTPRequestV3
+	// RequestTimeout auth.http.request.timeout, e.g. 0s
+	RequestTimeout string
+}
+
+// Map convert to the generic config form
+func (c *AuthHTTPConfigV3) Map() map[string]interface{} {
+	m := map[string]interface{}{}
+	putAuthHTTPRequest(m, authHTTPAuthReqKey, c.AuthReq)
+	putAuthHTTPRequest(m, authHTTPSuperReqKey, c.SuperReq)
+	putAuthHTTPRequest(m, authHTTPACLReqKey, c.ACLReq)
+	if c.RequestTimeout != "" {
+		m[authHTTPTimeoutKey] = c.RequestTimeout
+	}
+	return m
+}
+
+// NewAuthHTTPConfigV3 parse emqx_auth_http config from the generic config form
+func NewAuthHTTPConfigV3(m map[string]interface{}) *AuthHTTPConfigV3 {
+	return &AuthHTTPConfigV3{
+		AuthReq:        getAuthHTTPRequest(m, authHTTPAuthReqKey),
+		SuperReq:       getAuthHTTPRequest(m, authHTTPSuperReqKey),
+		ACLReq:         getAuthHTTPRequest(m, authHTTPACLReqKey),
+		RequestTimeout: configString(m, authHTTPTimeoutKey),
+	}
+}
+
+// GetWebHookConfig get emqx_web_hook config of a node
+func GetWebHookConfig(c Client, node string) (*WebHookConfigV3, error) {
+	resp, err := c.GetNodePluginConfig(node, PluginWebHook)
+	if err != nil {
+		return nil, err
+	}
+	return NewWebHookConfigV3(resp.Map()), nil
+}
+
+// UpdateWebHookConfig update emqx_web_hook config of a node
+func UpdateWebHookConfig(c Client, node string, config *WebHookConfigV3) (*NoContentResponse, error) {
+	return c.UpdateNodePluginConfig(node, PluginWebHook, config.Map())
+}
+
+// GetAuthHTTPConfig get emqx_auth_http config of a node
+func GetAuthHTTPConfig(c Client, node string) (*AuthHTTPConfigV3, error) {
+	resp, err := c.GetNodePluginConfig(node, PluginAuthHTTP)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuthHTTPConfigV3(resp.Map()), nil
+}
+
+// UpdateAuthHTTPConfig update emqx_auth_http config of a node
+func UpdateAuthHTTPConfig(c Client, node string, config *AuthHTTPConfigV3) (*NoContentResponse, error) {
+	return c.UpdateNodePluginConfig(node, PluginAuthHTTP, config.Map())
+}
+
+func putAuthHTTPRequest(m map[string]interface{}, key string, r AuthHTTPRequestV3) {
+	if r.URL == "" {
+		return
+	}
+	m[key] = r.URL
+	if r.Method != "" {
+		m[key+authHTTPMethodSuffix] = r.Method
+	}
+	if r.Params != "" {
+		m[key+authHTTPParamsSuffix] = r.Params
+	}
+}
+
+func getAuthHTTPRequest(m map[string]interface{}, key string) AuthHTTPRequestV3 {
+	return AuthHTTPRequestV3{
+		URL:    configString(m, key),
+		Method: configString(m, key+authHTTPMethodSuffix),
+		Params: configString(m, key+authHTTPParamsSuffix),
+	}
+}
+
+func configItemsToMap(items []ConfigItemV3) map[string]interface{} {
+	m := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		m[item.Key] = item.Value
+	}
+	return m
+}
+
+func configString(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
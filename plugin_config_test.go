@@ -0,0 +1,51 @@
+package emqx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebHookConfigV3(t *testing.T) {
+	m := map[string]interface{}{
+		"web.hook.api.url":                 "http://127.0.0.1:8991/mqtt/webhook",
+		"web.hook.rule.client.connected.1": `{"action": "on_client_connected"}`,
+	}
+	c := NewWebHookConfigV3(m)
+	if c.APIURL != "http://127.0.0.1:8991/mqtt/webhook" {
+		t.Fatal(c.APIURL)
+	}
+	if c.Rules["client.connected.1"] != `{"action": "on_client_connected"}` {
+		t.Fatal(c.Rules)
+	}
+	if !reflect.DeepEqual(c.Map(), m) {
+		t.Fatal(c.Map())
+	}
+}
+
+func TestWebHookConfigV3RulesOnly(t *testing.T) {
+	c := &WebHookConfigV3{Rules: map[string]string{"message.publish.1": `{"action": "on_message_publish"}`}}
+	want := map[string]interface{}{"web.hook.rule.message.publish.1": `{"action": "on_message_publish"}`}
+	// the url is left out so the update does not clear it
+	if !reflect.DeepEqual(c.Map(), want) {
+		t.Fatal(c.Map())
+	}
+}
+
+func TestAuthHTTPConfigV3(t *testing.T) {
+	m := map[string]interface{}{
+		"auth.http.auth_req":        "http://127.0.0.1:8991/mqtt/auth",
+		"auth.http.auth_req.method": "post",
+		"auth.http.auth_req.params": "clientid=%c,username=%u,password=%P",
+		"auth.http.acl_req":         "http://127.0.0.1:8991/mqtt/acl",
+	}
+	c := NewAuthHTTPConfigV3(m)
+	if c.AuthReq.Method != "post" || c.ACLReq.URL != "http://127.0.0.1:8991/mqtt/acl" {
+		t.Fatal(c)
+	}
+	if c.SuperReq.URL != "" {
+		t.Fatal(c.SuperReq)
+	}
+	if !reflect.DeepEqual(c.Map(), m) {
+		t.Fatal(c.Map())
+	}
+}
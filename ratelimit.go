@@ -0,0 +1,213 @@
+package emqx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass class of endpoints sharing a rate limit
+type EndpointClass string
+
+// Endpoint classes
+const (
+	// EndpointClassRead GET requests, e.g. listing sessions
+	EndpointClassRead EndpointClass = "read"
+	// EndpointClassPublish message publishing
+	EndpointClassPublish EndpointClass = "publish"
+	// EndpointClassWrite every other request, e.g. kicking a client
+	EndpointClassWrite EndpointClass = "write"
+)
+
+// RateLimit limit the requests sent to the broker, zero values mean no limit
+type RateLimit struct {
+	// Rate requests per second
+	Rate float64
+	// Burst requests sent at once before Rate applies, default to 1
+	Burst int
+	// MaxInFlight requests waiting for a response at once
+	MaxInFlight int
+	// MaxWait longest a request waits for a token and a slot before failing with *ErrRateLimited,
+	// default to the client Timeout, no limit for a RateLimitMiddleware built directly
+	MaxWait time.Duration
+}
+
+// ErrRateLimited request not sent because it waited longer than MaxWait for the rate limit
+type ErrRateLimited struct {
+	// Class endpoint class of the limit, empty for the limit of all requests
+	Class   EndpointClass
+	MaxWait time.Duration
+}
+
+func (e *ErrRateLimited) Error() string {
+	if e.Class == "" {
+		return fmt.Sprintf("emqx: rate limited, waited %s", e.MaxWait)
+	}
+	return fmt.Sprintf("emqx: rate limited for %s requests, waited %s", e.Class, e.MaxWait)
+}
+
+// classify the endpoint class of a request, publish is mqtt/publish and mqtt/publish_batch
+// up to v4, publish and publish/bulk for v5
+func classify(req *http.Request) EndpointClass {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	switch {
+	case strings.Contains(path, "/mqtt/publish"), strings.HasSuffix(path, "/publish"), strings.HasSuffix(path, "/publish/bulk"):
+		return EndpointClassPublish
+	case req.Method == http.MethodGet:
+		return EndpointClassRead
+	default:
+		return EndpointClassWrite
+	}
+}
+
+// RateLimitMiddleware limit requests with limit, and per endpoint class with classes.
+// Requests wait for both, and fail with the context error if it is done first,
+// or with *ErrRateLimited after MaxWait.
+// A request is in flight until its response body is closed.
+// The middleware may be shared by several clients to limit them together.
+func RateLimitMiddleware(limit RateLimit, classes map[EndpointClass]RateLimit) Middleware {
+	global := newLimiter("", limit)
+	limiters := map[EndpointClass]*limiter{}
+	for class, l := range classes {
+		limiters[class] = newLimiter(class, l)
+	}
+
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+
+			release, err := global.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if l, ok := limiters[classify(req)]; ok {
+				releaseClass, err := l.acquire(ctx)
+				if err != nil {
+					release()
+					return nil, err
+				}
+				releaseGlobal := release
+				release = func() {
+					releaseClass()
+					releaseGlobal()
+				}
+			}
+
+			resp, err := next(req)
+			if err != nil || resp.Body == nil {
+				release()
+				return resp, err
+			}
+			resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		}
+	}
+}
+
+// releaseBody release the in flight slots of a request once its response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// limiter token bucket and in flight cap
+type limiter struct {
+	class   EndpointClass
+	rate    float64
+	burst   float64
+	slots   chan struct{}
+	maxWait time.Duration
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(class EndpointClass, l RateLimit) *limiter {
+	lim := &limiter{class: class, rate: l.Rate, maxWait: l.MaxWait}
+	if l.Rate > 0 {
+		lim.burst = math.Max(float64(l.Burst), 1)
+		lim.tokens = lim.burst
+		lim.last = time.Now()
+	}
+	if l.MaxInFlight > 0 {
+		lim.slots = make(chan struct{}, l.MaxInFlight)
+	}
+	return lim
+}
+
+// acquire wait for a token and an in flight slot, at most maxWait,
+// release must be called once the request completes
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l.maxWait <= 0 {
+		return l.take(ctx)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, l.maxWait)
+	defer cancel()
+	release, err := l.take(waitCtx)
+	if err != nil && ctx.Err() == nil {
+		return nil, &ErrRateLimited{Class: l.class, MaxWait: l.maxWait}
+	}
+	return release, err
+}
+
+// take wait for a token and an in flight slot
+func (l *limiter) take(ctx context.Context) (func(), error) {
+	if err := l.wait(ctx); err != nil {
+		return nil, err
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// wait take a token, waiting for it to be refilled if needed
+func (l *limiter) wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	// reserve the token, the balance goes negative while waiting
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give the reservation back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
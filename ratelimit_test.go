@@ -0,0 +1,162 @@
+package emqx
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiterRate(t *testing.T) {
+	l := newLimiter("", RateLimit{Rate: 100, Burst: 2})
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 at once, then 4 at 10ms interval
+	if elapsed := time.Since(start); elapsed < time.Millisecond*35 {
+		t.Fatalf("rate not limited, took %s", elapsed)
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := newLimiter("", RateLimit{Rate: 1})
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := l.wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	l = newLimiter("", RateLimit{MaxInFlight: 1})
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, err := l.acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRateLimitMaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight, served int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&served, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 10)
+		w.Write([]byte(`{"code":0,"data":[]}`))
+	}))
+	defer server.Close()
+
+	c := NewAPIClient(ClientConfig{
+		BaseURL: server.URL,
+		// identical GETs would share a single request
+		DisableCoalescing: true,
+		RateLimits: map[EndpointClass]RateLimit{
+			EndpointClassRead: {MaxInFlight: 2},
+		},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.ListNodeSession("n1"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests := atomic.LoadInt32(&served); requests != 8 {
+		t.Fatalf("%d requests served, want 8", requests)
+	}
+	if maxInFlight > 2 {
+		t.Fatalf("%d requests in flight", maxInFlight)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	for path, want := range map[string]EndpointClass{
+		"/api/v3/mqtt/publish":       EndpointClassPublish,
+		"/api/v4/mqtt/publish_batch": EndpointClassPublish,
+		"/api/v5/publish":            EndpointClassPublish,
+		"/api/v5/publish/bulk":       EndpointClassPublish,
+		"/api/v5/clients/c1":         EndpointClassWrite,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080"+path, nil)
+		if got := classify(req); got != want {
+			t.Errorf("%s: got %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestRateLimitHoldsSlotUntilBodyClosed(t *testing.T) {
+	send := RateLimitMiddleware(RateLimit{MaxInFlight: 1}, nil)(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+	})
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v3/brokers/", nil)
+	first, err := send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first body is still being read
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if _, err := send(req.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	first.Body.Close()
+	second, err := send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Body.Close()
+}
+
+func TestRateLimitMaxWait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0,"data":[]}`))
+	}))
+	defer server.Close()
+
+	c := NewAPIClient(ClientConfig{
+		BaseURL: server.URL,
+		Timeout: time.Millisecond * 50,
+		// a single request, then one every 1000s
+		RateLimits: map[EndpointClass]RateLimit{EndpointClassRead: {Rate: 0.001}},
+	})
+	if _, err := c.ListCluster(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err := c.ListNodeStats()
+	if e, ok := err.(*ErrRateLimited); !ok || e.Class != EndpointClassRead {
+		t.Fatalf("expected *ErrRateLimited, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("caller waited %s", elapsed)
+	}
+}
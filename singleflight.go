@@ -0,0 +1,53 @@
+package emqx
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// call in flight or completed call of a flightGroup
+type call struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// flightGroup deduplicate concurrent calls with the same key,
+// callers arriving while a call is in flight share its result
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// do run fn once for concurrent callers of key, shared reports whether the result was shared.
+// Callers joining a call in flight stop waiting with the error of ctx once it is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (interface{}, error)) (val interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.val, c.err, true
+		case <-ctx.Done():
+			return nil, ctx.Err(), true
+		}
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	// seen by waiters if fn panics
+	c.err = errors.New("emqx: shared request panicked")
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
@@ -0,0 +1,82 @@
+package emqx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalescing(t *testing.T) {
+	for _, disabled := range []bool{false, true} {
+		var requests int32
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			<-release
+			w.Write([]byte(`{"code":0,"data":[{"clientid":"c1"}]}`))
+		}))
+
+		c := NewAPIClient(ClientConfig{BaseURL: server.URL, DisableCoalescing: disabled})
+		responses := make([]*ClusterConnectionResponseV3, 5)
+		var wg sync.WaitGroup
+		for i := range responses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				resp, err := c.GetClusterConnection("c1")
+				if err != nil {
+					t.Error(err)
+				}
+				responses[i] = resp
+			}(i)
+		}
+		// let the calls join the flight before releasing it
+		time.Sleep(time.Millisecond * 50)
+		close(release)
+		wg.Wait()
+		server.Close()
+
+		want := int32(1)
+		if disabled {
+			want = 5
+		}
+		if requests != want {
+			t.Fatalf("disabled %v: %d requests sent, want %d", disabled, requests, want)
+		}
+		// every caller decodes its own response
+		if responses[0] == responses[1] || len(responses[1].Data) != 1 {
+			t.Fatalf("unexpected responses %v", responses)
+		}
+	}
+}
+
+func TestCoalescingHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"code":0,"data":[]}`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL})
+	go a.ListCluster()
+	// let the leader start its request
+	time.Sleep(time.Millisecond * 50)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	start := time.Now()
+	var out ListClusterResponseV3
+	err := a.Do(ctx, http.MethodGet, "api/v3/brokers/", nil, nil, &out)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("follower waited %s past its deadline", elapsed)
+	}
+}
@@ -0,0 +1,196 @@
+package emqx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshot sections, keys of ClusterSnapshot.Errors
+const (
+	SnapshotBrokers   = "brokers"
+	SnapshotNodes     = "nodes"
+	SnapshotStats     = "stats"
+	SnapshotMetrics   = "metrics"
+	SnapshotListeners = "listeners"
+	SnapshotPlugins   = "plugins"
+	SnapshotAlarms    = "alarms"
+)
+
+// SnapshotCounts cluster wide counts
+type SnapshotCounts struct {
+	Nodes         int   `json:"nodes"`
+	Connections   int64 `json:"connections"`
+	Sessions      int64 `json:"sessions"`
+	Subscriptions int64 `json:"subscriptions"`
+	// Topics and Retained are replicated on every node, the largest node value is kept
+	Topics        int64 `json:"topics"`
+	Retained      int64 `json:"retained"`
+	Alarms        int   `json:"alarms"`
+	ActivePlugins int   `json:"active_plugins"`
+	Listeners     int   `json:"listeners"`
+}
+
+// ClusterSnapshot point in time state of a cluster, e.g. stored as JSON for incident review
+type ClusterSnapshot struct {
+	CapturedAt time.Time         `json:"captured_at"`
+	Brokers    []ClusterV3       `json:"brokers"`
+	Nodes      []NodeStatV3      `json:"nodes"`
+	Stats      []NodeStatsV3     `json:"stats"`
+	Metrics    []NodeMetricsV3   `json:"metrics"`
+	Listeners  []NodeListenersV3 `json:"listeners"`
+	Plugins    []NodePluginV3    `json:"plugins"`
+	Alarms     []NodeAlarmsV3    `json:"alarms"`
+	Counts     SnapshotCounts    `json:"counts"`
+	// Errors sections which could not be collected, by section
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Snapshot collect the state of the cluster concurrently, with the requests of the list methods of c
+// bound to ctx. Sections failing are left empty and reported in Errors, the snapshot is then returned
+// along with an error. If ctx is done before all sections are collected, the requests in flight are
+// canceled and no snapshot is returned.
+// Snapshot covers the v3 API, whose models ClusterSnapshot holds.
+func Snapshot(ctx context.Context, c Client) (*ClusterSnapshot, error) {
+	s := &ClusterSnapshot{CapturedAt: time.Now().UTC()}
+
+	// the list methods take no context, their requests are sent with Do
+	get := func(endpoint string, resp interface{}) error {
+		return c.Do(ctx, http.MethodGet, endpoint, nil, nil, resp)
+	}
+	sections := map[string]func() (int, error){
+		SnapshotBrokers: func() (int, error) {
+			var resp ListClusterResponseV3
+			if err := get("api/v3/brokers/", &resp); err != nil {
+				return 0, err
+			}
+			s.Brokers = resp.Data
+			return resp.Code, nil
+		},
+		SnapshotNodes: func() (int, error) {
+			var resp ListNodeStatResponseV3
+			if err := get("api/v3/nodes/", &resp); err != nil {
+				return 0, err
+			}
+			s.Nodes = resp.Data
+			return resp.Code, nil
+		},
+		SnapshotStats: func() (int, error) {
+			var resp ListClusterStatsResponseV3
+			if err := get("api/v3/stats/", &resp); err != nil {
+				return 0, err
+			}
+			s.Stats = resp.Data
+			return resp.Code, nil
+		},
+		SnapshotMetrics: func() (int, error) {
+			var resp ListClusterMetricsResponseV3
+			if err := get("api/v3/metrics/", &resp); err != nil {
+				return 0, err
+			}
+			s.Metrics = resp.Data
+			return resp.Code, nil
+		},
+		SnapshotListeners: func() (int, error) {
+			var resp ListClusterListenersResponseV3
+			if err := get("api/v3/listeners/", &resp); err != nil {
+				return 0, err
+			}
+			s.Listeners = resp.Data
+			return resp.Code, nil
+		},
+		SnapshotPlugins: func() (int, error) {
+			var resp ListClusterPluginResponseV3
+			if err := get("api/v3/plugins/", &resp); err != nil {
+				return 0, err
+			}
+			s.Plugins = resp.Data
+			return resp.Code, nil
+		},
+		SnapshotAlarms: func() (int, error) {
+			var resp ListClusterAlarmsResponseV3
+			if err := get("api/v3/alarms/present", &resp); err != nil {
+				return 0, err
+			}
+			s.Alarms = resp.Data
+			return resp.Code, nil
+		},
+	}
+
+	var (
+		mu   sync.Mutex
+		errs = map[string]string{}
+		done = make(chan string, len(sections))
+	)
+	for name, collect := range sections {
+		go func(name string, collect func() (int, error)) {
+			code, err := collect()
+			if err == nil {
+				err = codeError(code)
+			}
+			if err != nil {
+				mu.Lock()
+				errs[name] = err.Error()
+				mu.Unlock()
+			}
+			done <- name
+		}(name, collect)
+	}
+
+	for pending := len(sections); pending > 0; pending-- {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			// requests still running are canceled with ctx, done is buffered
+			return nil, ctx.Err()
+		}
+	}
+
+	s.Counts = snapshotCounts(s)
+	if len(errs) > 0 {
+		s.Errors = errs
+		failed := make([]string, 0, len(errs))
+		for name := range errs {
+			failed = append(failed, name)
+		}
+		sort.Strings(failed)
+		return s, fmt.Errorf("emqx: snapshot incomplete, failed sections: %s", strings.Join(failed, ", "))
+	}
+	return s, nil
+}
+
+func snapshotCounts(s *ClusterSnapshot) SnapshotCounts {
+	counts := SnapshotCounts{Nodes: len(s.Brokers)}
+	if counts.Nodes == 0 {
+		counts.Nodes = len(s.Nodes)
+	}
+	for _, n := range s.Stats {
+		counts.Connections += n.Stats["connections/count"]
+		counts.Sessions += n.Stats["sessions/count"]
+		counts.Subscriptions += n.Stats["subscriptions/count"]
+		if v := n.Stats["topics/count"]; v > counts.Topics {
+			counts.Topics = v
+		}
+		if v := n.Stats["retained/count"]; v > counts.Retained {
+			counts.Retained = v
+		}
+	}
+	for _, n := range s.Alarms {
+		counts.Alarms += len(n.Alarms)
+	}
+	for _, n := range s.Plugins {
+		for _, p := range n.Plugins {
+			if p.Active {
+				counts.ActivePlugins++
+			}
+		}
+	}
+	for _, n := range s.Listeners {
+		counts.Listeners += len(n.Listeners)
+	}
+	return counts
+}
@@ -0,0 +1,435 @@
+package emqx
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SnapshotChangeType kind of a node or listener change between snapshots
+type SnapshotChangeType string
+
+// Snapshot change types
+const (
+	SnapshotAdded   SnapshotChangeType = "added"
+	SnapshotChanged SnapshotChangeType = "changed"
+	SnapshotRemoved SnapshotChangeType = "removed"
+)
+
+// NodeChange node added, removed, or whose status or version changed
+type NodeChange struct {
+	Node string
+	Type SnapshotChangeType
+	// Field changed field, status or version, empty if added or removed
+	Field string
+	Old   string
+	New   string
+}
+
+func (c NodeChange) String() string {
+	switch c.Type {
+	case SnapshotAdded:
+		return fmt.Sprintf("+ %s", c.Node)
+	case SnapshotRemoved:
+		return fmt.Sprintf("- %s", c.Node)
+	}
+	return fmt.Sprintf("~ %s %s: %s => %s", c.Node, c.Field, c.Old, c.New)
+}
+
+// PluginChange plugin activated or deactivated on a node
+type PluginChange struct {
+	Node      string
+	Plugin    string
+	Activated bool
+}
+
+func (c PluginChange) String() string {
+	if c.Activated {
+		return fmt.Sprintf("+ %s %s activated", c.Node, c.Plugin)
+	}
+	return fmt.Sprintf("- %s %s deactivated", c.Node, c.Plugin)
+}
+
+// ListenerChange listener added, removed, or whose config changed on a node
+type ListenerChange struct {
+	Node string
+	// Listener listener ID, or ListenOn if the broker reports no ID
+	Listener string
+	Type     SnapshotChangeType
+	// Field changed setting, e.g. max_conns, empty if added or removed
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+func (c ListenerChange) String() string {
+	switch c.Type {
+	case SnapshotAdded:
+		return fmt.Sprintf("+ %s %s", c.Node, c.Listener)
+	case SnapshotRemoved:
+		return fmt.Sprintf("- %s %s", c.Node, c.Listener)
+	}
+	return fmt.Sprintf("~ %s %s %s: %v => %v", c.Node, c.Listener, c.Field, c.Old, c.New)
+}
+
+// MetricDelta change of a metric or count between two snapshots
+type MetricDelta struct {
+	// Node empty for cluster wide counts
+	Node  string
+	Name  string
+	Old   int64
+	New   int64
+	Delta int64
+}
+
+func (d MetricDelta) String() string {
+	node := d.Node
+	if node == "" {
+		node = "cluster"
+	}
+	return fmt.Sprintf("%s %s: %d => %d (%+d)", node, d.Name, d.Old, d.New, d.Delta)
+}
+
+// SnapshotDiff changes between two cluster snapshots.
+// Plugins, listeners and metrics are compared on nodes present in both snapshots only,
+// nodes added or removed are reported in Nodes. Sections listed in the Errors of
+// either snapshot are not compared.
+type SnapshotDiff struct {
+	// From capture time of the older snapshot
+	From time.Time
+	// To capture time of the newer snapshot
+	To        time.Time
+	Nodes     []NodeChange
+	Plugins   []PluginChange
+	Listeners []ListenerChange
+	// Metrics cluster wide count deltas, e.g. connections
+	Metrics []MetricDelta
+	// NodeMetrics per node counter deltas, e.g. bytes.received. Counters grow between any two
+	// snapshots, so they are not part of String nor Empty.
+	NodeMetrics []MetricDelta
+}
+
+// Empty report whether nothing changed
+func (d *SnapshotDiff) Empty() bool {
+	return len(d.Nodes) == 0 && len(d.Plugins) == 0 && len(d.Listeners) == 0 && len(d.Metrics) == 0
+}
+
+// String human readable report, e.g. to post after a deployment
+func (d *SnapshotDiff) String() string {
+	var b bytes.Buffer
+	if d.From.IsZero() || d.To.IsZero() {
+		b.WriteString("Changes:\n")
+	} else {
+		fmt.Fprintf(&b, "Changes from %s to %s:\n", d.From.Format(time.RFC3339), d.To.Format(time.RFC3339))
+	}
+	if d.Empty() {
+		b.WriteString("  no changes\n")
+		return b.String()
+	}
+
+	section := func(title string, n int, line func(i int) string) {
+		if n == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s:\n", title)
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "  %s\n", line(i))
+		}
+	}
+	section("Nodes", len(d.Nodes), func(i int) string { return d.Nodes[i].String() })
+	section("Plugins", len(d.Plugins), func(i int) string { return d.Plugins[i].String() })
+	section("Listeners", len(d.Listeners), func(i int) string { return d.Listeners[i].String() })
+	section("Metrics", len(d.Metrics), func(i int) string { return d.Metrics[i].String() })
+	return b.String()
+}
+
+// DiffSnapshots compare two snapshots, from being the older one
+func DiffSnapshots(from, to *ClusterSnapshot) *SnapshotDiff {
+	d := &SnapshotDiff{From: from.CapturedAt, To: to.CapturedAt}
+
+	failed := func(sections ...string) bool {
+		return snapshotFailed(from, to, sections...)
+	}
+
+	oldNodes, newNodes := snapshotNodes(from), snapshotNodes(to)
+	common := map[string]bool{}
+	for node := range oldNodes {
+		if _, ok := newNodes[node]; ok {
+			common[node] = true
+		}
+	}
+	if !failed(SnapshotBrokers, SnapshotNodes) {
+		d.Nodes = diffNodes(oldNodes, newNodes)
+	}
+
+	if !failed(SnapshotPlugins) {
+		for _, c := range diffPlugins(from.Plugins, to.Plugins) {
+			if common[c.Node] {
+				d.Plugins = append(d.Plugins, c)
+			}
+		}
+	}
+	if !failed(SnapshotListeners) {
+		for _, c := range diffListeners(from.Listeners, to.Listeners) {
+			if common[c.Node] {
+				d.Listeners = append(d.Listeners, c)
+			}
+		}
+	}
+
+	d.Metrics = diffCounts(from, to)
+	if !failed(SnapshotMetrics) {
+		oldMetrics, newMetrics := metricsByNode(from.Metrics), metricsByNode(to.Metrics)
+		for _, node := range sortedKeys(common) {
+			d.NodeMetrics = append(d.NodeMetrics, diffMetrics(node, oldMetrics[node], newMetrics[node])...)
+		}
+	}
+	return d
+}
+
+// snapshotFailed report whether every listed section failed in at least one of the two snapshots
+func snapshotFailed(from, to *ClusterSnapshot, sections ...string) bool {
+	for _, name := range sections {
+		if from.Errors[name] == "" && to.Errors[name] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffPlugins compare two results of ListClusterPlugins, on nodes present in both
+func DiffPlugins(from, to *ListClusterPluginResponseV3) []PluginChange {
+	return diffPlugins(from.Data, to.Data)
+}
+
+// DiffListeners compare two results of ListClusterListeners, on nodes present in both
+func DiffListeners(from, to *ListClusterListenersResponseV3) []ListenerChange {
+	return diffListeners(from.Data, to.Data)
+}
+
+// snapshotNodes status and version by node, from brokers, or node stats if brokers failed
+func snapshotNodes(s *ClusterSnapshot) map[string][2]string {
+	nodes := map[string][2]string{}
+	for _, n := range s.Brokers {
+		nodes[n.Node] = [2]string{n.NodeStatus, n.Version}
+	}
+	if len(s.Brokers) == 0 {
+		for _, n := range s.Nodes {
+			nodes[n.Node] = [2]string{n.NodeStatus, ""}
+		}
+	}
+	return nodes
+}
+
+func diffNodes(from, to map[string][2]string) []NodeChange {
+	changes := []NodeChange{}
+	for node, old := range from {
+		cur, ok := to[node]
+		if !ok {
+			changes = append(changes, NodeChange{Node: node, Type: SnapshotRemoved})
+			continue
+		}
+		if old[0] != cur[0] {
+			changes = append(changes, NodeChange{Node: node, Type: SnapshotChanged, Field: "status", Old: old[0], New: cur[0]})
+		}
+		if old[1] != cur[1] && old[1] != "" && cur[1] != "" {
+			changes = append(changes, NodeChange{Node: node, Type: SnapshotChanged, Field: "version", Old: old[1], New: cur[1]})
+		}
+	}
+	for node := range to {
+		if _, ok := from[node]; !ok {
+			changes = append(changes, NodeChange{Node: node, Type: SnapshotAdded})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Node < changes[j].Node
+	})
+	return changes
+}
+
+func diffPlugins(from, to []NodePluginV3) []PluginChange {
+	active := func(data []NodePluginV3) map[string]map[string]bool {
+		nodes := map[string]map[string]bool{}
+		for _, n := range data {
+			plugins := map[string]bool{}
+			for _, p := range n.Plugins {
+				plugins[p.Name] = p.Active
+			}
+			nodes[n.Node] = plugins
+		}
+		return nodes
+	}
+	oldNodes, newNodes := active(from), active(to)
+
+	changes := []PluginChange{}
+	for node, old := range oldNodes {
+		cur, ok := newNodes[node]
+		if !ok {
+			continue
+		}
+		names := map[string]bool{}
+		for name := range old {
+			names[name] = true
+		}
+		for name := range cur {
+			names[name] = true
+		}
+		for name := range names {
+			if old[name] != cur[name] {
+				changes = append(changes, PluginChange{Node: node, Plugin: name, Activated: cur[name]})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Node != changes[j].Node {
+			return changes[i].Node < changes[j].Node
+		}
+		return changes[i].Plugin < changes[j].Plugin
+	})
+	return changes
+}
+
+func diffListeners(from, to []NodeListenersV3) []ListenerChange {
+	byID := func(data []NodeListenersV3) map[string]map[string]ListenerV3 {
+		nodes := map[string]map[string]ListenerV3{}
+		for _, n := range data {
+			listeners := map[string]ListenerV3{}
+			for _, l := range n.Listeners {
+				listeners[listenerID(l)] = l
+			}
+			nodes[n.Node] = listeners
+		}
+		return nodes
+	}
+	oldNodes, newNodes := byID(from), byID(to)
+
+	changes := []ListenerChange{}
+	for node, old := range oldNodes {
+		cur, ok := newNodes[node]
+		if !ok {
+			continue
+		}
+		for id, o := range old {
+			n, ok := cur[id]
+			if !ok {
+				changes = append(changes, ListenerChange{Node: node, Listener: id, Type: SnapshotRemoved})
+				continue
+			}
+			fields := []struct {
+				name     string
+				old, new interface{}
+			}{
+				{"protocol", o.Protocol, n.Protocol},
+				{"listen_on", o.ListenOn, n.ListenOn},
+				{"acceptors", o.Acceptors, n.Acceptors},
+				{"max_conns", o.MaxConns, n.MaxConns},
+			}
+			for _, f := range fields {
+				if f.old != f.new {
+					changes = append(changes, ListenerChange{Node: node, Listener: id, Type: SnapshotChanged, Field: f.name, Old: f.old, New: f.new})
+				}
+			}
+		}
+		for id := range cur {
+			if _, ok := old[id]; !ok {
+				changes = append(changes, ListenerChange{Node: node, Listener: id, Type: SnapshotAdded})
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Node != changes[j].Node {
+			return changes[i].Node < changes[j].Node
+		}
+		if changes[i].Listener != changes[j].Listener {
+			return changes[i].Listener < changes[j].Listener
+		}
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func listenerID(l ListenerV3) string {
+	if l.Identifier != "" {
+		return l.Identifier
+	}
+	return l.ListenOn
+}
+
+// metricsByNode metrics of each node by name, e.g. messages.received
+func metricsByNode(data []NodeMetricsV3) map[string]Metrics {
+	nodes := map[string]Metrics{}
+	for _, n := range data {
+		metrics := Metrics{}
+		for _, m := range n.Metrics {
+			// counters only, encoding cannot fail
+			_ = addMetricsV3(metrics, m)
+		}
+		nodes[n.Node] = metrics
+	}
+	return nodes
+}
+
+// diffMetrics non zero deltas of a node, sorted by name
+func diffMetrics(node string, from, to map[string]int64) []MetricDelta {
+	names := map[string]bool{}
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+	deltas := []MetricDelta{}
+	for _, name := range sortedKeys(names) {
+		if delta := to[name] - from[name]; delta != 0 {
+			deltas = append(deltas, MetricDelta{Node: node, Name: name, Old: from[name], New: to[name], Delta: delta})
+		}
+	}
+	return deltas
+}
+
+// countSections sections each count is computed from, nodes from brokers or node stats
+var countSections = map[string][]string{
+	"nodes":          {SnapshotBrokers, SnapshotNodes},
+	"connections":    {SnapshotStats},
+	"sessions":       {SnapshotStats},
+	"subscriptions":  {SnapshotStats},
+	"topics":         {SnapshotStats},
+	"retained":       {SnapshotStats},
+	"alarms":         {SnapshotAlarms},
+	"active_plugins": {SnapshotPlugins},
+	"listeners":      {SnapshotListeners},
+}
+
+// diffCounts cluster wide count deltas, skipping counts of failed sections
+func diffCounts(from, to *ClusterSnapshot) []MetricDelta {
+	values := func(c SnapshotCounts) map[string]int64 {
+		v := map[string]int64{
+			"nodes":          int64(c.Nodes),
+			"connections":    c.Connections,
+			"sessions":       c.Sessions,
+			"subscriptions":  c.Subscriptions,
+			"topics":         c.Topics,
+			"retained":       c.Retained,
+			"alarms":         int64(c.Alarms),
+			"active_plugins": int64(c.ActivePlugins),
+			"listeners":      int64(c.Listeners),
+		}
+		for name, sections := range countSections {
+			if snapshotFailed(from, to, sections...) {
+				delete(v, name)
+			}
+		}
+		return v
+	}
+	return diffMetrics("", values(from.Counts), values(to.Counts))
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
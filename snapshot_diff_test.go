@@ -0,0 +1,116 @@
+package emqx
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	captured := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	from := &ClusterSnapshot{
+		CapturedAt: captured,
+		Brokers:    []ClusterV3{{Node: "n1", NodeStatus: "Running", Version: "v3.2.7"}, {Node: "n2", NodeStatus: "Running"}},
+		Plugins: []NodePluginV3{
+			{Node: "n1", Plugins: []PluginV3{{Name: PluginWebHook, Active: true}, {Name: PluginAuthHTTP}}},
+			{Node: "n2", Plugins: []PluginV3{{Name: PluginWebHook, Active: true}}},
+		},
+		Listeners: []NodeListenersV3{
+			{Node: "n1", Listeners: []ListenerV3{{ListenOn: "0.0.0.0:1883", Protocol: "mqtt:tcp", MaxConns: 1024}}},
+		},
+		Metrics: []NodeMetricsV3{{Node: "n1", Metrics: []MetricsV3{{BytesReceived: 10}}}},
+		Counts:  SnapshotCounts{Nodes: 2, Connections: 5},
+	}
+	to := &ClusterSnapshot{
+		CapturedAt: captured.Add(time.Hour),
+		Brokers:    []ClusterV3{{Node: "n1", NodeStatus: "Running", Version: "v3.2.8"}, {Node: "n3", NodeStatus: "Running"}},
+		Plugins: []NodePluginV3{
+			{Node: "n1", Plugins: []PluginV3{{Name: PluginWebHook}, {Name: PluginAuthHTTP, Active: true}}},
+			{Node: "n3", Plugins: []PluginV3{{Name: PluginWebHook, Active: true}}},
+		},
+		Listeners: []NodeListenersV3{
+			{Node: "n1", Listeners: []ListenerV3{
+				{ListenOn: "0.0.0.0:1883", Protocol: "mqtt:tcp", MaxConns: 2048},
+				{ListenOn: "0.0.0.0:8883", Protocol: "mqtt:ssl"},
+			}},
+		},
+		Metrics: []NodeMetricsV3{{Node: "n1", Metrics: []MetricsV3{{BytesReceived: 25}}}},
+		Counts:  SnapshotCounts{Nodes: 2, Connections: 8},
+	}
+
+	d := DiffSnapshots(from, to)
+	if len(d.Nodes) != 3 || d.Nodes[0].Field != "version" || d.Nodes[1].Type != SnapshotRemoved || d.Nodes[2].Type != SnapshotAdded {
+		t.Fatalf("unexpected node changes %v", d.Nodes)
+	}
+	// n2 and n3 plugins are not compared
+	if len(d.Plugins) != 2 || !d.Plugins[0].Activated || d.Plugins[0].Plugin != PluginAuthHTTP || d.Plugins[1].Activated {
+		t.Fatalf("unexpected plugin changes %v", d.Plugins)
+	}
+	if len(d.Listeners) != 2 || d.Listeners[0].Field != "max_conns" || d.Listeners[1].Type != SnapshotAdded {
+		t.Fatalf("unexpected listener changes %v", d.Listeners)
+	}
+	if len(d.Metrics) != 1 || d.Metrics[0].Name != "connections" {
+		t.Fatalf("unexpected metric deltas %v", d.Metrics)
+	}
+	if len(d.NodeMetrics) != 1 || d.NodeMetrics[0].Name != "bytes.received" || d.NodeMetrics[0].Delta != 15 {
+		t.Fatalf("unexpected node metric deltas %v", d.NodeMetrics)
+	}
+
+	report := d.String()
+	for _, line := range []string{
+		"Changes from 2020-01-01T00:00:00Z to 2020-01-01T01:00:00Z:",
+		"~ n1 version: v3.2.7 => v3.2.8",
+		"- n2",
+		"+ n1 emqx_auth_http activated",
+		"~ n1 0.0.0.0:1883 max_conns: 1024 => 2048",
+		"+ n1 0.0.0.0:8883",
+		"cluster connections: 5 => 8 (+3)",
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report is missing %q:\n%s", line, report)
+		}
+	}
+	// counters grow between any two snapshots
+	if strings.Contains(report, "bytes.received") {
+		t.Errorf("report lists node counters:\n%s", report)
+	}
+
+	if d := DiffSnapshots(to, to); !d.Empty() || !strings.Contains(d.String(), "no changes") {
+		t.Fatalf("expected no changes, got %s", d)
+	}
+}
+
+func TestDiffSnapshotsJSON(t *testing.T) {
+	var from, to ClusterSnapshot
+	if err := json.Unmarshal([]byte(`{"brokers":[{"node":"n1","node_status":"Running","version":"v3.2.7"}]}`), &from); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"brokers":[{"node":"n1","node_status":"Stopped","version":"v3.2.7"}]}`), &to); err != nil {
+		t.Fatal(err)
+	}
+	d := DiffSnapshots(&from, &to)
+	if len(d.Nodes) != 1 || d.Nodes[0].Field != "status" || d.Nodes[0].Old != "Running" || d.Nodes[0].New != "Stopped" {
+		t.Fatalf("unexpected node changes %v", d.Nodes)
+	}
+}
+
+func TestDiffSnapshotsSkipsFailedSections(t *testing.T) {
+	from := &ClusterSnapshot{
+		Brokers: []ClusterV3{{Node: "n1", NodeStatus: "Running"}},
+		Plugins: []NodePluginV3{{Node: "n1", Plugins: []PluginV3{{Name: PluginWebHook, Active: true}}}},
+		Counts:  SnapshotCounts{Nodes: 1, Connections: 5, Alarms: 2, ActivePlugins: 1},
+	}
+	// alarms and stats could not be listed, plugins are reported as empty
+	to := &ClusterSnapshot{
+		Brokers: []ClusterV3{{Node: "n1", NodeStatus: "Running"}},
+		Counts:  SnapshotCounts{Nodes: 1, Connections: 0, Alarms: 0, ActivePlugins: 0},
+		Errors:  map[string]string{SnapshotAlarms: "unavailable", SnapshotStats: "unavailable", SnapshotPlugins: "unavailable"},
+	}
+	if d := DiffSnapshots(from, to); !d.Empty() {
+		t.Fatalf("expected failed sections to be skipped, got %s", d)
+	}
+	if d := DiffSnapshots(to, from); !d.Empty() {
+		t.Fatalf("expected failed sections to be skipped, got %s", d)
+	}
+}
@@ -0,0 +1,78 @@
+package emqx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	responses := map[string]string{
+		"/api/v3/brokers/": `{"code":0,"data":[{"node":"n1","node_status":"Running"},{"node":"n2","node_status":"Running"}]}`,
+		"/api/v3/nodes/":   `{"code":0,"data":[{"node":"n1","connections":2},{"node":"n2","connections":1}]}`,
+		"/api/v3/stats/": `{"code":0,"data":[
+			{"node":"n1","stats":{"connections/count":2,"topics/count":5}},
+			{"node":"n2","stats":{"connections/count":1,"topics/count":5}}]}`,
+		"/api/v3/metrics/":   `{"code":0,"data":[{"node":"n1","metrics":[{"bytes/received":10}]}]}`,
+		"/api/v3/listeners/": `{"code":0,"data":[{"node":"n1","listeners":[{"listen_on":"1883","protocol":"mqtt:tcp"}]}]}`,
+		"/api/v3/plugins/": `{"code":0,"data":[{"node":"n1","plugins":[
+			{"name":"emqx_web_hook","active":true},{"name":"emqx_auth_http","active":false}]}]}`,
+		"/api/v3/alarms/present": `{"code":102,"message":"unavailable"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responses[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	s, err := Snapshot(context.Background(), NewAPIClient(ClientConfig{BaseURL: server.URL}))
+	if err == nil || s == nil {
+		t.Fatalf("expected partial snapshot, got %v %v", s, err)
+	}
+	if len(s.Errors) != 1 || s.Errors[SnapshotAlarms] == "" {
+		t.Fatalf("unexpected errors %v", s.Errors)
+	}
+	want := SnapshotCounts{Nodes: 2, Connections: 3, Topics: 5, ActivePlugins: 1, Listeners: 1}
+	if s.Counts != want {
+		t.Fatalf("counts %+v, want %+v", s.Counts, want)
+	}
+	if s.CapturedAt.IsZero() || s.Metrics[0].Metrics[0].BytesReceived != 10 {
+		t.Fatalf("unexpected snapshot %+v", s)
+	}
+
+	content, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ClusterSnapshot
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Counts != want || !decoded.CapturedAt.Equal(s.CapturedAt) || len(decoded.Plugins[0].Plugins) != 2 {
+		t.Fatalf("snapshot not preserved by JSON: %s", content)
+	}
+}
+
+func TestSnapshotCanceled(t *testing.T) {
+	canceled := make(chan struct{}, 7)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		canceled <- struct{}{}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	s, err := Snapshot(ctx, NewAPIClient(ClientConfig{BaseURL: server.URL}))
+	if s != nil || err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v %v", s, err)
+	}
+	// the requests in flight are canceled rather than left running
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("requests not canceled")
+	}
+}
@@ -0,0 +1,188 @@
+package emqx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSConfig TLS options of the connection to the EMQX API.
+// Files take precedence over PEM bytes, and are loaded again when their modification time changes.
+type TLSConfig struct {
+	// CAFile CA bundle verifying the server, default to the system roots
+	CAFile string
+	// CAPEM PEM encoded CA bundle
+	CAPEM []byte
+	// CertFile client certificate, for mutual TLS
+	CertFile string
+	// KeyFile client certificate key
+	KeyFile string
+	// CertPEM PEM encoded client certificate
+	CertPEM []byte
+	// KeyPEM PEM encoded client certificate key
+	KeyPEM []byte
+	// ServerName override the server name verified, default to the base url host
+	ServerName string
+	// MinVersion minimum TLS version, e.g. tls.VersionTLS13, default to tls.VersionTLS12
+	MinVersion uint16
+}
+
+// files watched for changes
+func (c TLSConfig) files() []string {
+	files := []string{}
+	for _, f := range []string{c.CAFile, c.CertFile, c.KeyFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// load build the tls.Config from files and PEM bytes
+func (c TLSConfig) load() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: c.MinVersion,
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	ca, err := pemOrFile(c.CAPEM, c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("emqx: no certificate found in CA bundle")
+		}
+		cfg.RootCAs = pool
+	}
+
+	cert, err := pemOrFile(c.CertPEM, c.CertFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := pemOrFile(c.KeyPEM, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(cert) > 0 || len(key) > 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("emqx: load client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return cfg, nil
+}
+
+func pemOrFile(pem []byte, path string) ([]byte, error) {
+	if path == "" {
+		return pem, nil
+	}
+	return ioutil.ReadFile(path)
+}
+
+// TLSTransport http.RoundTripper connecting with a TLSConfig, reloading it when its files change.
+// If a reload fails, e.g. while a certificate is being replaced, the previous config is kept
+// and the reload is attempted again on the next request.
+type TLSTransport struct {
+	config TLSConfig
+
+	mu       sync.Mutex
+	rt       *http.Transport
+	modTimes map[string]time.Time
+}
+
+// NewTLSTransport create a transport, the config is loaded on the first request.
+// A LoginAuthenticator logs in over the transport of the client using it, see LoginConfig.TLS.
+func NewTLSTransport(c TLSConfig) *TLSTransport {
+	return &TLSTransport{
+		config:   c,
+		modTimes: map[string]time.Time{},
+	}
+}
+
+// RoundTrip implement http.RoundTripper
+func (t *TLSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt, err := t.transport()
+	if err != nil {
+		return nil, err
+	}
+	return rt.RoundTrip(req)
+}
+
+// CloseIdleConnections close idle connections of the current transport
+func (t *TLSTransport) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rt != nil {
+		t.rt.CloseIdleConnections()
+	}
+}
+
+// transport return the current transport, rebuilt if the files changed
+func (t *TLSTransport) transport() (*http.Transport, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	modTimes := map[string]time.Time{}
+	changed := t.rt == nil
+	for _, f := range t.config.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			if t.rt != nil {
+				return t.rt, nil
+			}
+			return nil, err
+		}
+		modTimes[f] = info.ModTime()
+		if !info.ModTime().Equal(t.modTimes[f]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return t.rt, nil
+	}
+
+	cfg, err := t.config.load()
+	if err != nil {
+		if t.rt != nil {
+			return t.rt, nil
+		}
+		return nil, err
+	}
+
+	if t.rt != nil {
+		// new connections use the new config
+		t.rt.CloseIdleConnections()
+	}
+	t.rt = newHTTPTransport(cfg)
+	t.modTimes = modTimes
+	return t.rt, nil
+}
+
+// newHTTPTransport transport with the settings of http.DefaultTransport
+func newHTTPTransport(cfg *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       cfg,
+	}
+}
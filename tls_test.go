@@ -0,0 +1,177 @@
+package emqx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert create a certificate signed by parent, self signed if parent is nil
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestTLSMutualAuth(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	serverCert := newTestCert(t, "emqx.internal", ca)
+	clientCert := newTestCert(t, "client", ca)
+
+	pair, err := tls.X509KeyPair(serverCert.certPEM, serverCert.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":0,"data":[]}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "emqx-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	// start with an unrelated CA, the server is not trusted
+	if err := ioutil.WriteFile(caFile, newTestCert(t, "other", nil).certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewAPIClient(ClientConfig{
+		BaseURL: server.URL,
+		TLS: &TLSConfig{
+			CAFile:     caFile,
+			CertPEM:    clientCert.certPEM,
+			KeyPEM:     clientCert.keyPEM,
+			ServerName: "emqx.internal",
+		},
+	})
+	if _, err := c.ListNodeStats(); err == nil {
+		t.Fatal("expected untrusted server")
+	}
+
+	if err := ioutil.WriteFile(caFile, ca.certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(caFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListNodeStats(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLSMissingClientCert(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	c := NewAPIClient(ClientConfig{
+		BaseURL: "https://127.0.0.1:1",
+		TLS:     &TLSConfig{CAPEM: ca.certPEM, CertPEM: ca.certPEM},
+	})
+	if _, err := c.ListNodeStats(); err == nil {
+		t.Fatal("expected invalid client certificate")
+	}
+}
+
+func TestTLSLogin(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	serverCert := newTestCert(t, "emqx.internal", ca)
+	pair, err := tls.X509KeyPair(serverCert.certPEM, serverCert.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/auth" {
+			w.Write([]byte(`{"code":0,"data":{"token":"t1"}}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"code":0,"data":[]}`))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{pair}}
+	server.StartTLS()
+	defer server.Close()
+
+	config := &TLSConfig{CAPEM: ca.certPEM, ServerName: "emqx.internal"}
+	login := LoginConfig{BaseURL: server.URL, Username: "admin", Password: "public"}
+
+	// login over the TLS config of the client
+	c := NewAPIClient(ClientConfig{BaseURL: server.URL, TLS: config, Authenticator: NewLoginAuthenticator(login)})
+	if _, err := c.ListNodeStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	// or its own
+	login.TLS = config
+	auth := NewLoginAuthenticator(login)
+	if token, err := auth.Authorization(); err != nil || token != "Bearer t1" {
+		t.Fatal(token, err)
+	}
+}
@@ -0,0 +1,339 @@
+package emqx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// transport HTTP layer shared by the clients of all API versions
+type transport struct {
+	// BaseURL emqx RESTFul address
+	BaseURL    string
+	httpClient *http.Client
+	roundTrip  RoundTripFunc
+
+	instrumentation Instrumentation
+	// flight coalesce identical GETs in flight, nil if disabled
+	flight *flightGroup
+
+	mu      sync.Mutex
+	auth    Authenticator
+	catalog *Catalog
+	// catalogStale reload the catalog before its next use, plugins were started or stopped
+	catalogStale bool
+	// catalogErr last failed catalog load, retried after catalogRetryDelay
+	catalogErr      error
+	catalogFailedAt time.Time
+
+	// requireCatalog fail fast requests under catalogPrefix the catalog does not serve
+	requireCatalog bool
+	catalogPrefix  string
+	// loadCatalog list the endpoints served by the broker, nil if the API version has no catalog
+	loadCatalog func() (*Catalog, error)
+
+	// decodeError turn an error response into an error, nil keeps decoding it into resp
+	decodeError func(statusCode int, content []byte) error
+}
+
+func newTransport(c ClientConfig) *transport {
+	t := &transport{
+		httpClient: &http.Client{},
+	}
+
+	if c.BaseURL == "" {
+		c.BaseURL = "http://localhost:8080"
+	}
+	t.BaseURL = c.BaseURL
+
+	if c.Timeout == 0 {
+		c.Timeout = time.Second * 5
+	}
+	t.httpClient.Timeout = c.Timeout
+
+	if c.TLS != nil {
+		t.httpClient.Transport = NewTLSTransport(*c.TLS)
+	}
+
+	if c.Credentials == nil {
+		c.Credentials = StaticCredentials{AppID: c.AppID, AppSecret: c.AppSecret}
+	}
+	if c.Authenticator == nil {
+		c.Authenticator = NewBasicAuthenticator(c.Credentials)
+	}
+	t.auth = c.Authenticator
+	t.shareTransport(c.Authenticator)
+
+	middlewares := c.Middlewares
+	if c.RateLimit != (RateLimit{}) || len(c.RateLimits) > 0 {
+		// outermost, so waiting is not part of the logged latency
+		// waiting is bounded by the client timeout, which only applies once the request is sent
+		global := c.RateLimit
+		if global.MaxWait == 0 {
+			global.MaxWait = c.Timeout
+		}
+		classes := make(map[EndpointClass]RateLimit, len(c.RateLimits))
+		for class, l := range c.RateLimits {
+			if l.MaxWait == 0 {
+				l.MaxWait = c.Timeout
+			}
+			classes[class] = l
+		}
+		limit := RateLimitMiddleware(global, classes)
+		middlewares = append([]Middleware{limit}, middlewares...)
+	}
+	if c.CircuitBreaker != nil {
+		// fail fast before waiting for the rate limit
+		middlewares = append([]Middleware{c.CircuitBreaker.Middleware()}, middlewares...)
+	}
+	if c.Logger != nil {
+		// innermost, so the logged request is the one sent
+		middlewares = append(middlewares[:len(middlewares):len(middlewares)], logMiddleware(c.Logger, c.LogBodies))
+	}
+	t.roundTrip = chain(t.httpClient.Do, middlewares)
+	t.instrumentation = c.Instrumentation
+	t.requireCatalog = c.RequireCatalog
+	if !c.DisableCoalescing {
+		t.flight = &flightGroup{}
+	}
+
+	return t
+}
+
+// SetCredentials replace the application credentials used by subsequent requests
+func (t *transport) SetCredentials(appID, appSecret string) {
+	t.SetCredentialsProvider(StaticCredentials{AppID: appID, AppSecret: appSecret})
+}
+
+// SetCredentialsProvider replace the credentials provider used by subsequent requests
+func (t *transport) SetCredentialsProvider(p CredentialsProvider) {
+	t.SetAuthenticator(NewBasicAuthenticator(p))
+}
+
+// SetAuthenticator replace the authenticator used by subsequent requests
+func (t *transport) SetAuthenticator(auth Authenticator) {
+	t.shareTransport(auth)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.auth = auth
+}
+
+// shareTransport let a login authenticator login over the TLS transport of the client
+func (t *transport) shareTransport(auth Authenticator) {
+	if l, ok := auth.(*LoginAuthenticator); ok && t.httpClient.Transport != nil {
+		l.useTransport(t.httpClient.Transport)
+	}
+}
+
+func (t *transport) authenticator() Authenticator {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.auth
+}
+
+// secret the secret of the current authenticator, redacted from logs
+func (t *transport) secret() string {
+	if s, ok := t.authenticator().(secretHolder); ok {
+		return s.secret()
+	}
+	return ""
+}
+
+// Requester send requests to endpoints the clients do not model,
+// implemented by the clients of all API versions
+type Requester interface {
+	Do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error
+}
+
+// Do send a request to an endpoint the client does not model, with the same
+// base url, authentication and error decoding as the modeled operations.
+// path is relative to the base url, e.g. api/v3/banned/, query may be nil.
+// body is sent as is if it is []byte or json.RawMessage, encoded as JSON otherwise, nil sends no body.
+// The JSON response is decoded into out, nil discards it.
+func (t *transport) Do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		payload = b
+	case json.RawMessage:
+		payload = b
+	default:
+		var err error
+		if payload, err = json.Marshal(b); err != nil {
+			return err
+		}
+	}
+
+	endpoint := strings.TrimPrefix(path, "/")
+	if len(query) > 0 {
+		sep := "?"
+		if strings.Contains(endpoint, "?") {
+			sep = "&"
+		}
+		endpoint += sep + query.Encode()
+	}
+
+	if out == nil {
+		out = &json.RawMessage{}
+	}
+	return t.makeRequestContext(ctx, method, endpoint, payload, out)
+}
+
+// makeRequest makeRequest
+func (t *transport) makeRequest(method, endpoint string, payload []byte, resp interface{}) error {
+	return t.makeRequestContext(context.Background(), method, endpoint, payload, resp)
+}
+
+// makeRequestContext makeRequest bound to ctx
+func (t *transport) makeRequestContext(ctx context.Context, method, endpoint string, payload []byte, resp interface{}) error {
+	if t.instrumentation == nil {
+		_, err := t.send(ctx, method, endpoint, payload, resp)
+		return err
+	}
+
+	ctx, end := t.instrumentation.StartRequest(ctx, newRequestInfo(method, endpoint, t.getCatalog()))
+	start := time.Now()
+	result, err := t.send(ctx, method, endpoint, payload, resp)
+	result.Duration = time.Since(start)
+	result.Err = err
+	end(result)
+	return err
+}
+
+// send perform the request, retrying once with a new session on 401
+func (t *transport) send(ctx context.Context, method, endpoint string, payload []byte, resp interface{}) (RequestResult, error) {
+	var result RequestResult
+	err, loadErr := t.checkCatalog(method, endpoint)
+	result.CatalogErr = loadErr
+	if err != nil {
+		result.ErrorType = ErrorTypeUnsupported
+		return result, err
+	}
+
+	auth := t.authenticator()
+	reqURL := fmt.Sprintf("%s/%s", t.BaseURL, endpoint)
+
+	for retried := false; ; retried = true {
+		token, err := auth.Authorization()
+		if err != nil {
+			result.ErrorType = ErrorTypeAuth
+			return result, err
+		}
+
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewBuffer(payload)
+		}
+		request, err := http.NewRequest(method, reqURL, body)
+		if err != nil {
+			result.ErrorType = ErrorTypeRequest
+			return result, err
+		}
+		request = request.WithContext(withSecret(withBaseURL(ctx, t.BaseURL), t.secret))
+
+		request.Header = http.Header{
+			"Authorization": []string{token},
+			"Content-Type":  []string{"application/json"},
+		}
+
+		statusCode, content, err := t.exchange(request)
+		if err != nil {
+			result.ErrorType = ErrorTypeNetwork
+			if _, ok := err.(*ErrCircuitOpen); ok {
+				result.ErrorType = ErrorTypeCircuitOpen
+			}
+			return result, err
+		}
+		result.StatusCode = statusCode
+
+		// session expired on the broker side, login again once
+		if inv, ok := auth.(invalidator); ok && statusCode == http.StatusUnauthorized {
+			inv.Invalidate()
+			if !retried {
+				continue
+			}
+		}
+
+		if t.instrumentation != nil {
+			result.Code = responseCode(content)
+			result.ErrorType = classifyResponse(statusCode, result.Code)
+		}
+
+		if t.decodeError != nil {
+			if err := t.decodeError(statusCode, content); err != nil {
+				return result, err
+			}
+		}
+
+		// e.g. 204 No Content
+		if len(content) == 0 && statusCode < http.StatusBadRequest {
+			return result, nil
+		}
+
+		err = json.Unmarshal(content, resp)
+		if err != nil {
+			if result.ErrorType == "" {
+				result.ErrorType = ErrorTypeDecode
+			}
+			return result, errors.New(string(content))
+		}
+
+		return result, nil
+	}
+}
+
+// exchanged status and body of a response
+type exchanged struct {
+	statusCode int
+	content    []byte
+}
+
+// exchange send the request and read its response.
+// Identical GETs in flight share a single round trip, each caller decodes the shared body.
+func (t *transport) exchange(request *http.Request) (int, []byte, error) {
+	if t.flight == nil || request.Method != http.MethodGet {
+		return t.readResponse(request)
+	}
+
+	key := request.URL.String() + " " + request.Header.Get("Authorization")
+	v, err, shared := t.flight.do(request.Context(), key, func() (interface{}, error) {
+		statusCode, content, err := t.readResponse(request)
+		return exchanged{statusCode, content}, err
+	})
+	if err != nil {
+		// the request we joined was canceled by its caller, send ours
+		if shared && request.Context().Err() == nil && isContextError(err) {
+			return t.readResponse(request)
+		}
+		return 0, nil, err
+	}
+	e := v.(exchanged)
+	return e.statusCode, e.content, nil
+}
+
+func (t *transport) readResponse(request *http.Request) (int, []byte, error) {
+	response, err := t.roundTrip(request)
+	if err != nil {
+		return 0, nil, err
+	}
+	content, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	return response.StatusCode, content, nil
+}
+
+func isContextError(err error) bool {
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
+	}
+	return err == context.Canceled || err == context.DeadlineExceeded
+}
@@ -0,0 +1,41 @@
+package emqx
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v3/banned/" || r.URL.Query().Get("_limit") != "10" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		if string(body) != `{"as":"clientid","who":"c1"}` {
+			t.Errorf("unexpected body %s", body)
+		}
+		if r.Header.Get("Authorization") != "Basic YXBwOnNlY3JldA==" {
+			t.Errorf("unexpected authorization %s", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"code":0,"data":{"who":"c1"}}`))
+	}))
+	defer server.Close()
+
+	a := NewAPIClient(ClientConfig{BaseURL: server.URL, AppID: "app", AppSecret: "secret"})
+	var out struct {
+		Code int
+		Data map[string]string
+	}
+	err := a.Do(context.Background(), http.MethodPost, "/api/v3/banned/", url.Values{"_limit": []string{"10"}},
+		map[string]string{"who": "c1", "as": "clientid"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Data["who"] != "c1" {
+		t.Fatal(out)
+	}
+}